@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"connections/pkg/ai"
+	"connections/pkg/calibration"
+	"connections/pkg/grouper"
+	"connections/pkg/solver"
+)
+
+func main() {
+	archive := flag.String("archive", "", "JSON archive of solved puzzles")
+	output := flag.String("out", "calibration.json", "where to write the learned calibration")
+	useAI := flag.Bool("ai", false, "also observe AI groups (uses LOCAL_AI_URL, GEMINI_API_KEY, ANTHROPIC_API_KEY or OPENAI_API_KEY and makes one request per puzzle)")
+	embeddings := flag.String("embeddings", os.Getenv("EMBEDDINGS_FILE"), "word-embedding file, so the embedding strategy is calibrated too")
+	grouperConfig := flag.String("grouper-config", "", "JSON file weighting pattern strategies; use the one the CLI solves with, since calibration learns the weighted confidences")
+	flag.Parse()
+
+	if *archive == "" {
+		fmt.Fprintln(os.Stderr, "Usage: calibrate -archive puzzles.json [-out calibration.json] [-grouper-config config.json] [-ai]")
+		os.Exit(2)
+	}
+
+	if *embeddings != "" {
+		if err := grouper.RegisterEmbeddings(*embeddings); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	var groupCfg grouper.Config
+	if *grouperConfig != "" {
+		var err error
+		groupCfg, err = grouper.LoadConfig(*grouperConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	puzzles, err := calibration.LoadArchive(*archive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	s, err := newSolver(*useAI)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	s.SetGrouper(grouper.NewWithConfig(groupCfg))
+
+	var observations []calibration.Observation
+	for i, puzzle := range puzzles {
+		obs, err := s.Observe(ctx, puzzle)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error observing puzzle %d: %v\n", i+1, err)
+			os.Exit(1)
+		}
+		observations = append(observations, obs...)
+	}
+
+	correct := 0
+	for _, o := range observations {
+		if o.Correct {
+			correct++
+		}
+	}
+	fmt.Printf("Observed %d candidate groups across %d puzzles (%d correct)\n", len(observations), len(puzzles), correct)
+
+	if err := calibration.Fit(observations).Save(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %s\n", *output)
+}
+
+// newSolver picks the same AI provider the CLI would, if AI is requested,
+// configured from the same environment variables
+func newSolver(useAI bool) (*solver.Solver, error) {
+	if !useAI {
+		return solver.New(), nil
+	}
+
+	var provider ai.Provider
+	var prefix string
+	switch {
+	case os.Getenv("LOCAL_AI_URL") != "":
+		prefix = "LOCAL_AI"
+	case os.Getenv("GEMINI_API_KEY") != "":
+		prefix = "GEMINI"
+	case os.Getenv("ANTHROPIC_API_KEY") != "":
+		prefix = "ANTHROPIC"
+	case os.Getenv("OPENAI_API_KEY") != "":
+		prefix = "OPENAI"
+	default:
+		fmt.Println("No API key set, observing pattern matching only")
+		return solver.New(), nil
+	}
+
+	opts, err := ai.EnvOptions(prefix)
+	if err != nil {
+		return nil, err
+	}
+	switch prefix {
+	case "LOCAL_AI":
+		provider = ai.NewLocalProviderWithOptions(opts)
+	case "GEMINI":
+		provider = ai.NewGeminiProviderWithOptions(os.Getenv("GEMINI_API_KEY"), opts)
+	case "ANTHROPIC":
+		provider = ai.NewClaudeProviderWithOptions(os.Getenv("ANTHROPIC_API_KEY"), opts)
+	default:
+		provider = ai.NewOpenAIProviderWithOptions(os.Getenv("OPENAI_API_KEY"), opts)
+	}
+	return solver.NewWithProvider(provider), nil
+}
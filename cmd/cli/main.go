@@ -2,27 +2,121 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 
+	"connections/pkg/ai"
+	"connections/pkg/calibration"
+	"connections/pkg/grouper"
 	"connections/pkg/solver"
 )
 
 func main() {
-	// Try to load .env file if it exists (ignore errors if not found)
+	// Try to load .env file if it exists (ignore errors if not found); flag
+	// defaults read the environment, so this comes first
 	loadEnvFile()
 
+	interactive := flag.Bool("interactive", false, "play a live puzzle guess by guess using the game's feedback")
+	alternatives := flag.Int("alternatives", 2, "number of alternative solutions to show")
+	ensemble := flag.Bool("ensemble", false, "ask every provider with an API key and merge their answers by vote")
+	strategy := flag.String("strategy", "oneshot", "AI prompting strategy: oneshot or iterative")
+	grouperConfig := flag.String("grouper-config", "", "JSON file enabling, disabling and weighting pattern strategies")
+	calibrationFile := flag.String("calibration", "", "calibration file from cmd/calibrate, fitted with the same -grouper-config, to adjust confidences")
+	embeddings := flag.String("embeddings", os.Getenv("EMBEDDINGS_FILE"), "word-embedding file (GloVe text or from cmd/quantize) for offline semantic grouping")
+	localURL := flag.String("local-url", os.Getenv("LOCAL_AI_URL"), "OpenAI-compatible endpoint of a local model, e.g. "+ai.DefaultLocalBaseURL+" for Ollama")
+	localModel := flag.String("local-model", os.Getenv("LOCAL_AI_MODEL"), "local model name (default "+ai.DefaultLocalModel+")")
+	model := flag.String("model", "", "model for the selected provider, overriding GEMINI_MODEL, ANTHROPIC_MODEL or OPENAI_MODEL")
+	baseURL := flag.String("base-url", "", "API base URL for the selected provider, overriding GEMINI_URL, ANTHROPIC_URL or OPENAI_URL")
+	temperature := flag.Float64("temperature", -1, "sampling temperature (default AI_TEMPERATURE, else the service's own)")
+	maxTokens := flag.Int("max-tokens", 0, "reply length limit (default AI_MAX_TOKENS, else the provider's own)")
+	aiTimeout := flag.Duration("ai-timeout", 0, "timeout for each AI request (default AI_TIMEOUT, else 60s)")
+	aiRetries := flag.Int("ai-retries", 0, "retries of rate-limited or failed AI requests; negative for none (default AI_MAX_RETRIES, else 3)")
+	var headers []string
+	flag.Func("header", "extra AI request header as \"Name: value\"; may be repeated (adds to AI_HEADERS)", func(value string) error {
+		if _, _, err := ai.ParseHeader(value); err != nil {
+			return err
+		}
+		headers = append(headers, value)
+		return nil
+	})
+	timeout := flag.Duration("timeout", 0, "overall deadline for solving (e.g. 30s); 0 means no deadline")
+	flag.Parse()
+
+	if *ensemble && (*model != "" || *baseURL != "") {
+		fmt.Fprintln(os.Stderr, "Error: -model and -base-url pick one provider's settings; use the <PROVIDER>_MODEL and <PROVIDER>_URL variables with -ensemble")
+		os.Exit(2)
+	}
+
+	// Command-line settings override the environment's
+	overrides := ai.Options{Model: *model, BaseURL: *baseURL, MaxTokens: *maxTokens, Timeout: *aiTimeout, MaxRetries: *aiRetries}
+	if *temperature >= 0 {
+		overrides.Temperature = temperature
+	}
+	for _, header := range headers {
+		name, value, _ := ai.ParseHeader(header)
+		if overrides.Headers == nil {
+			overrides.Headers = make(map[string]string)
+		}
+		overrides.Headers[name] = value
+	}
+
+	aiStrategy, err := solver.ParseAIStrategy(*strategy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Register the embedding strategy before the config can refer to it
+	if *embeddings != "" {
+		if err := grouper.RegisterEmbeddings(*embeddings); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	var groupCfg grouper.Config
+	if *grouperConfig != "" {
+		groupCfg, err = grouper.LoadConfig(*grouperConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	var calibrator *calibration.Calibrator
+	if *calibrationFile != "" {
+		calibrator, err = calibration.Load(*calibrationFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	// Ctrl-C cancels any in-flight AI request
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	fmt.Println("🔗 NYTimes Connections Solver")
 	fmt.Println("================================")
 
-	// Check for AI API keys (Gemini first, then Claude, then OpenAI)
+	// A configured local model comes first, then the API keys (Gemini,
+	// then Claude, then OpenAI)
 	geminiKey := os.Getenv("GEMINI_API_KEY")
 	claudeKey := os.Getenv("ANTHROPIC_API_KEY")
 	openaiKey := os.Getenv("OPENAI_API_KEY")
 
 	var aiMode string
-	if geminiKey != "" {
+	if *ensemble && countSet(*localURL, geminiKey, claudeKey, openaiKey) > 1 {
+		aiMode = "ensemble"
+		fmt.Println("✨ AI ensemble mode enabled (voting across providers)")
+	} else if *localURL != "" {
+		aiMode = "local"
+		fmt.Printf("✨ AI mode enabled (using local model at %s)\n", *localURL)
+	} else if geminiKey != "" {
 		aiMode = "gemini"
 		fmt.Println("✨ AI mode enabled (using Google Gemini)")
 	} else if claudeKey != "" {
@@ -34,11 +128,13 @@ func main() {
 	} else {
 		aiMode = "none"
 		fmt.Println("📊 Pattern matching mode")
-		fmt.Println("   Set GEMINI_API_KEY, ANTHROPIC_API_KEY, or OPENAI_API_KEY for AI")
+		fmt.Println("   Set GEMINI_API_KEY, ANTHROPIC_API_KEY, OPENAI_API_KEY or LOCAL_AI_URL for AI")
 	}
 	fmt.Println()
 
-	words, err := readWords()
+	scanner := bufio.NewScanner(os.Stdin)
+
+	words, err := readWords(scanner)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading words: %v\n", err)
 		os.Exit(1)
@@ -55,33 +151,81 @@ func main() {
 	}
 	fmt.Println()
 
+	keys := map[string]string{"gemini": geminiKey, "claude": claudeKey, "openai": openaiKey}
+	optionsFor := func(name string) []ai.Options {
+		if name == "local" {
+			// The local model's own flags sit under the generic overrides
+			return []ai.Options{{BaseURL: *localURL, Model: *localModel}, overrides}
+		}
+		return []ai.Options{overrides}
+	}
+
 	// Create solver (with or without AI)
 	var s *solver.Solver
 	switch aiMode {
-	case "gemini":
-		s = solver.NewWithGemini(geminiKey)
-	case "claude":
-		s = solver.NewWithClaude(claudeKey)
-	case "openai":
-		s = solver.NewWithAI(openaiKey)
-	default:
+	case "ensemble":
+		var providers []solver.NamedProvider
+		for _, name := range []string{"local", "gemini", "claude", "openai"} {
+			if (name == "local" && *localURL == "") || (name != "local" && keys[name] == "") {
+				continue
+			}
+			provider, err := newProvider(name, keys[name], optionsFor(name)...)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(2)
+			}
+			providers = append(providers, solver.NamedProvider{Name: name, Provider: provider})
+		}
+		s = solver.NewEnsemble(providers...)
+	case "none":
 		s = solver.New()
+	default:
+		provider, err := newProvider(aiMode, keys[aiMode], optionsFor(aiMode)...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		s = solver.NewWithProvider(provider)
+	}
+	s.SetAIStrategy(aiStrategy)
+	s.SetGrouper(grouper.NewWithConfig(groupCfg))
+	s.SetCalibrator(calibrator)
+
+	// The deadline bounds solving, including the analysis an interactive
+	// session starts with
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if *interactive {
+		if err := playInteractive(ctx, s, words, scanner); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	// Solve the puzzle
-	groups, err := s.Solve(words)
+	ranking, err := s.SolveRanked(ctx, words, *alternatives+1)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error solving: %v\n", err)
 		os.Exit(1)
 	}
+	groups := ranking.Partitions[0].Groups
 
 	// Display results
-	fmt.Println("Suggested Groups:")
-	fmt.Println("=================")
+	fmt.Println("Suggested Groups (easiest first):")
+	fmt.Println("=================================")
 	for i, group := range groups {
-		fmt.Printf("\nGroup %d: %s", i+1, group.Theme)
+		fmt.Printf("\nGroup %d: %s %s", i+1, difficultyEmoji(group.Difficulty), group.Theme)
 		if group.Source == "ai" {
 			fmt.Printf(" [AI]")
+		} else if strings.HasPrefix(group.Source, "ai:") {
+			fmt.Printf(" [AI: %s]", strings.TrimPrefix(group.Source, "ai:"))
+		} else if group.Source == "elimination" {
+			fmt.Printf(" [Elimination]")
 		} else {
 			fmt.Printf(" [Pattern]")
 		}
@@ -92,46 +236,248 @@ func main() {
 		}
 		fmt.Printf("Confidence: %.0f%%\n", group.Confidence*100)
 	}
+
+	printAlternatives(ranking)
 }
 
-func readWords() ([]string, error) {
-	fmt.Println("Enter 16 words (one per line, or all on one line separated by spaces/commas):")
+// printAlternatives shows runner-up solutions and words that fit several groups
+func printAlternatives(ranking *solver.Ranking) {
+	if len(ranking.Partitions) > 1 {
+		fmt.Println()
+		fmt.Println("Alternative Solutions:")
+		fmt.Println("======================")
+		for i, p := range ranking.Partitions[1:] {
+			fmt.Printf("\nAlternative %d (score %.2f):\n", i+1, p.Score)
+			for _, group := range p.Groups {
+				fmt.Printf("  %s: %s\n", group.Theme, strings.Join(group.Words, ", "))
+			}
+		}
+	}
+
+	if ambiguous := ranking.Ambiguous(); len(ambiguous) > 0 {
+		fmt.Println()
+		fmt.Println("Watch Out For:")
+		fmt.Println("==============")
+		for _, fit := range ambiguous {
+			fmt.Printf("⚠️  %s fits %s\n", fit.Word, strings.Join(fit.Themes(), " / "))
+		}
+	}
+}
+
+func readWords(scanner *bufio.Scanner) ([]string, error) {
+	fmt.Println("Enter 16 words (one per line, or all on one line separated by commas or spaces):")
 
-	scanner := bufio.NewScanner(os.Stdin)
 	var words []string
+	for len(words) < 16 && scanner.Scan() {
+		words = append(words, splitEntries(scanner.Text(), 16)...)
+	}
 
-	// Read first line
-	if !scanner.Scan() {
-		return nil, scanner.Err()
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 
-	line := scanner.Text()
+	return words, nil
+}
 
-	// Check if all words are on one line
-	line = strings.ReplaceAll(line, ",", " ")
-	parts := strings.Fields(line)
+// splitEntries reads the tiles on one line of input. Commas separate tiles,
+// so "BALD EAGLE, CAFÉ" is two. Without commas the line is one tile, such as
+// "BALD EAGLE", unless splitting it on spaces gives exactly want words.
+func splitEntries(line string, want int) []string {
+	var entries []string
+	if strings.Contains(line, ",") {
+		for _, entry := range strings.Split(line, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
 
-	if len(parts) == 16 {
-		return parts, nil
-	} else if len(parts) == 1 {
-		words = append(words, parts[0])
-	} else if len(parts) > 1 {
-		words = append(words, parts...)
+	if fields := strings.Fields(line); len(fields) == want {
+		return fields
+	}
+	if entry := strings.TrimSpace(line); entry != "" {
+		entries = append(entries, entry)
 	}
+	return entries
+}
 
-	// Read remaining lines until we have 16 words
-	for len(words) < 16 && scanner.Scan() {
-		line := scanner.Text()
-		line = strings.ReplaceAll(line, ",", " ")
-		parts := strings.Fields(line)
-		words = append(words, parts...)
+// playInteractive walks through a live puzzle, suggesting one guess at a time
+// and recording the feedback the game gave for it
+func playInteractive(ctx context.Context, s *solver.Solver, words []string, scanner *bufio.Scanner) error {
+	session, err := s.NewSession(ctx, words)
+	if err != nil {
+		return err
 	}
 
-	if err := scanner.Err(); err != nil {
+	for !session.Done() {
+		fmt.Printf("Remaining: %s\n", strings.Join(session.Remaining(), ", "))
+		fmt.Printf("Mistakes left: %d\n\n", session.MistakesLeft())
+
+		var guess []string
+		suggestion, err := session.NextGuess()
+		if err != nil {
+			fmt.Printf("No suggestion available: %v\n", err)
+		} else {
+			guess = suggestion.Words
+			fmt.Printf("Suggested guess: %s\n", strings.Join(guess, ", "))
+			fmt.Printf("Theme: %s %s (confidence %.0f%%)\n", difficultyEmoji(suggestion.Difficulty), suggestion.Theme, suggestion.Confidence*100)
+		}
+
+		for {
+			fmt.Println("Enter feedback: [c]orrect, [o]ne away, [w]rong, 4 words for a different guess, or [q]uit:")
+			if !scanner.Scan() {
+				return scanner.Err()
+			}
+			line := strings.TrimSpace(scanner.Text())
+
+			if strings.EqualFold(line, "q") {
+				return nil
+			}
+
+			if parts := splitEntries(line, 4); len(parts) == 4 {
+				guess = parts
+				fmt.Printf("Guess: %s\n", strings.Join(guess, ", "))
+				continue
+			}
+
+			feedback, ok := parseFeedback(line)
+			if !ok {
+				fmt.Println("Unrecognized input.")
+				continue
+			}
+			if guess == nil {
+				fmt.Println("Enter the 4 words you guessed first.")
+				continue
+			}
+
+			if err := session.Record(guess, feedback); err != nil {
+				fmt.Printf("Could not record guess: %v\n", err)
+				continue
+			}
+			fmt.Printf("Recorded: %s\n\n", feedback)
+			break
+		}
+	}
+
+	if session.Won() {
+		fmt.Printf("🎉 Solved with %d mistake(s)!\n", session.Mistakes())
+	} else {
+		fmt.Println("Out of mistakes.")
+	}
+	for i, group := range session.Solved() {
+		fmt.Printf("Group %d: %s\n", i+1, strings.Join(group.Words, ", "))
+		if group.Theme != "" {
+			fmt.Printf("Theme: %s\n", group.Theme)
+		}
+	}
+
+	return nil
+}
+
+// difficultyEmoji returns the game's coloured square for a difficulty tier
+func difficultyEmoji(d solver.Difficulty) string {
+	switch d {
+	case solver.Yellow:
+		return "🟨"
+	case solver.Green:
+		return "🟩"
+	case solver.Blue:
+		return "🟦"
+	case solver.Purple:
+		return "🟪"
+	default:
+		return "⬜"
+	}
+}
+
+// parseFeedback converts user input into game feedback
+func parseFeedback(input string) (solver.Feedback, bool) {
+	switch strings.ToLower(input) {
+	case "c", "correct":
+		return solver.Correct, true
+	case "o", "one away", "one-away":
+		return solver.OneAway, true
+	case "w", "wrong":
+		return solver.Wrong, true
+	default:
+		return 0, false
+	}
+}
+
+// envPrefixes names each provider's MODEL and URL environment variables
+var envPrefixes = map[string]string{
+	"local":  "LOCAL_AI",
+	"gemini": "GEMINI",
+	"claude": "ANTHROPIC",
+	"openai": "OPENAI",
+}
+
+// newProvider creates the named provider with options from the environment,
+// then each set of overrides applied in turn
+func newProvider(name, apiKey string, overrides ...ai.Options) (ai.Provider, error) {
+	opts, err := ai.EnvOptions(envPrefixes[name])
+	if err != nil {
 		return nil, err
 	}
+	for _, o := range overrides {
+		opts = mergeOptions(opts, o)
+	}
 
-	return words, nil
+	switch name {
+	case "local":
+		return ai.NewLocalProviderWithOptions(opts), nil
+	case "gemini":
+		return ai.NewGeminiProviderWithOptions(apiKey, opts), nil
+	case "claude":
+		return ai.NewClaudeProviderWithOptions(apiKey, opts), nil
+	default:
+		return ai.NewOpenAIProviderWithOptions(apiKey, opts), nil
+	}
+}
+
+// mergeOptions returns base with every field set in override replaced
+func mergeOptions(base, override ai.Options) ai.Options {
+	if override.Model != "" {
+		base.Model = override.Model
+	}
+	if override.BaseURL != "" {
+		base.BaseURL = override.BaseURL
+	}
+	if override.Temperature != nil {
+		base.Temperature = override.Temperature
+	}
+	if override.MaxTokens > 0 {
+		base.MaxTokens = override.MaxTokens
+	}
+	if override.Timeout > 0 {
+		base.Timeout = override.Timeout
+	}
+	if override.MaxRetries != 0 {
+		base.MaxRetries = override.MaxRetries
+	}
+	if len(override.Headers) > 0 {
+		headers := make(map[string]string)
+		for name, value := range base.Headers {
+			headers[name] = value
+		}
+		for name, value := range override.Headers {
+			headers[name] = value
+		}
+		base.Headers = headers
+	}
+	return base
+}
+
+// countSet returns how many of the values are non-empty
+func countSet(values ...string) int {
+	count := 0
+	for _, v := range values {
+		if v != "" {
+			count++
+		}
+	}
+	return count
 }
 
 // loadEnvFile loads environment variables from .env file if it exists
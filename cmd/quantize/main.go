@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"connections/pkg/embedding"
+)
+
+func main() {
+	input := flag.String("in", "", "GloVe-style text vectors, one \"word v1 v2 ...\" line per word")
+	output := flag.String("out", "embeddings.bin", "where to write the quantized model")
+	limit := flag.Int("max", 100000, "keep only the first (most frequent) words; 0 keeps all")
+	flag.Parse()
+
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "Usage: quantize -in glove.6B.100d.txt [-out embeddings.bin] [-max 100000]")
+		os.Exit(2)
+	}
+
+	f, err := os.Open(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = f.Close() }()
+
+	model, err := embedding.ReadText(f, *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := model.Save(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %d words of %d dimensions to %s\n", model.Len(), model.Dim(), *output)
+}
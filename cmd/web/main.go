@@ -2,12 +2,17 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
+	"connections/pkg/ai"
+	"connections/pkg/grouper"
 	"connections/pkg/solver"
 
 	"github.com/joho/godotenv"
@@ -22,9 +27,12 @@ type SolveRequest struct {
 
 // Response payload for the API
 type SolveResponse struct {
-	Success bool    `json:"success"`
-	Groups  []Group `json:"groups,omitempty"`
-	Error   string  `json:"error,omitempty"`
+	Success      bool            `json:"success"`
+	Groups       []Group         `json:"groups,omitempty"`
+	Alternatives []Alternative   `json:"alternatives,omitempty"`
+	Ambiguous    []AmbiguousWord `json:"ambiguous,omitempty"`
+	Warning      string          `json:"warning,omitempty"` // Why AI did not help, if it did not
+	Error        string          `json:"error,omitempty"`
 }
 
 type Group struct {
@@ -32,8 +40,24 @@ type Group struct {
 	Theme       string   `json:"theme"`
 	Explanation string   `json:"explanation"`
 	Confidence  float64  `json:"confidence"`
+	Difficulty  string   `json:"difficulty"`
 }
 
+// Alternative is a runner-up solution
+type Alternative struct {
+	Groups []Group `json:"groups"`
+	Score  float64 `json:"score"`
+}
+
+// AmbiguousWord is a word that fits more than one candidate group
+type AmbiguousWord struct {
+	Word   string   `json:"word"`
+	Themes []string `json:"themes"`
+}
+
+// alternativeCount is the number of runner-up solutions returned by /solve
+const alternativeCount = 2
+
 func main() {
 	// Load .env file if it exists (for local development)
 	// 🔴 BREAKPOINT HERE - Line 38: Set breakpoint to see .env loading
@@ -50,6 +74,14 @@ func main() {
 		}
 	}
 
+	// Offline semantic grouping, if a word-embedding file is configured
+	if path := os.Getenv("EMBEDDINGS_FILE"); path != "" {
+		if err := grouper.RegisterEmbeddings(path); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("✅ Loaded word embeddings from %s", path)
+	}
+
 	// Get port from environment (Heroku provides this)
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -81,7 +113,12 @@ func handleHome(w http.ResponseWriter, _ *http.Request) {
 				button:hover { background: #45a049; }
 				#result { margin-top: 20px; padding: 20px; background: #f5f5f5; border-radius: 5px; }
 				.group { margin: 10px 0; padding: 10px; background: white; border-left: 4px solid #4CAF50; }
+				.group.yellow { border-left-color: #f9df6d; }
+				.group.green { border-left-color: #a0c35a; }
+				.group.blue { border-left-color: #b0c4ef; }
+				.group.purple { border-left-color: #ba81c5; }
 				.error { color: red; }
+				.warning { color: #b36b00; }
 				.grid { display: grid; grid-template-columns: repeat(4, 1fr); gap: 10px; }
 				.grid input { padding: 10px; font-size: 16px; width: 100%; box-sizing: border-box; }
 			`)),
@@ -117,6 +154,31 @@ func handleHome(w http.ResponseWriter, _ *http.Request) {
 			),
 			h.Div(h.ID("result")),
 			h.Script(g.Raw(`
+			function renderWarning(data) {
+				return data.warning ? '<p class="warning">⚠️ ' + data.warning + '</p>' : '';
+			}
+
+			function renderExtras(data) {
+				let html = '';
+				if (data.ambiguous && data.ambiguous.length > 0) {
+					html += '<h3>⚠️ Watch out for:</h3>';
+					data.ambiguous.forEach((a) => {
+						html += '<div>' + a.word + ' fits ' + a.themes.join(' / ') + '</div>';
+					});
+				}
+				if (data.alternatives && data.alternatives.length > 0) {
+					html += '<h3>Alternative solutions:</h3>';
+					data.alternatives.forEach((alt, i) => {
+						html += '<div class="group"><strong>Alternative ' + (i+1) + '</strong> (score ' + alt.score.toFixed(2) + ')<br>';
+						alt.groups.forEach((group) => {
+							html += group.theme + ': ' + group.words.join(', ') + '<br>';
+						});
+						html += '</div>';
+					});
+				}
+				return html;
+			}
+
 			async function solve() {
 				const words = [];
 				for (let i = 0; i < 16; i++) {
@@ -133,7 +195,7 @@ func handleHome(w http.ResponseWriter, _ *http.Request) {
 					return;
 				}
 
-				document.getElementById('result').innerHTML = '<p>Analyzing with Gemini AI...</p>';
+				document.getElementById('result').innerHTML = '<p>Analyzing...</p>';
 
 				try {
 					const response = await fetch('/solve', {
@@ -145,18 +207,20 @@ func handleHome(w http.ResponseWriter, _ *http.Request) {
 					const data = await response.json();
 
 					if (data.success) {
-						let html = '<h2>✅ Found ' + data.groups.length + ' groups:</h2>';
+						let html = '<h2>✅ Found ' + data.groups.length + ' groups (easiest first):</h2>';
 						data.groups.forEach((group, i) => {
-							html += '<div class="group">';
+							html += '<div class="group ' + group.difficulty + '">';
 							html += '<strong>Group ' + (i+1) + ':</strong> ' + group.theme + '<br>';
 							html += '<strong>Words:</strong> ' + group.words.join(', ') + '<br>';
 							html += '<strong>Explanation:</strong> ' + group.explanation + '<br>';
-							html += '<strong>Confidence:</strong> ' + Math.round(group.confidence * 100) + '%';
+							html += '<strong>Confidence:</strong> ' + Math.round(group.confidence * 100) + '%<br>';
+							html += '<strong>Difficulty:</strong> ' + group.difficulty;
 							html += '</div>';
 						});
-						document.getElementById('result').innerHTML = html;
+						html += renderExtras(data);
+						document.getElementById('result').innerHTML = renderWarning(data) + html;
 					} else {
-						document.getElementById('result').innerHTML = '<p class="error">Error: ' + data.error + '</p>';
+						document.getElementById('result').innerHTML = renderWarning(data) + '<p class="error">Error: ' + data.error + '</p>';
 					}
 				} catch (error) {
 					document.getElementById('result').innerHTML = '<p class="error">Error: ' + error.message + '</p>';
@@ -197,48 +261,157 @@ func handleSolve(w http.ResponseWriter, r *http.Request) {
 		req.Words[i] = strings.ToUpper(strings.TrimSpace(word))
 	}
 
-	// Solve the puzzle with Gemini AI
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	var s *solver.Solver
-	if apiKey != "" {
-		log.Printf("Using Gemini AI with API key: %s...", apiKey[:20])
-		s = solver.NewWithGemini(apiKey)
-	} else {
-		log.Printf("No API key found, using pattern matching")
-		s = solver.New()
+	s, err := newSolver()
+	if err != nil {
+		log.Printf("Invalid AI settings: %v", err)
+		respondJSON(w, SolveResponse{
+			Success: false,
+			Error:   "Invalid AI settings: " + err.Error(),
+		})
+		return
 	}
 
-	groups, err := s.Solve(req.Words)
+	// Stop calling the AI provider if the browser goes away
+	ranking, err := s.SolveRanked(r.Context(), req.Words, alternativeCount+1)
+	warning := aiWarning(w, s.AIError())
 	if err != nil {
+		var groups []solver.Group
+		if ranking != nil {
+			groups = ranking.Partitions[0].Groups
+		}
 		log.Printf("Solver error: %v (found %d groups)", err, len(groups))
 
 		// If we got some groups but not all 4, return them with a warning
 		if len(groups) > 0 {
-			respGroups := make([]Group, len(groups))
-			for i, grp := range groups {
-				respGroups[i] = Group{
-					Words:       grp.Words,
-					Theme:       grp.Theme,
-					Explanation: grp.Explanation,
-					Confidence:  grp.Confidence,
-				}
-			}
 			respondJSON(w, SolveResponse{
-				Success: false,
-				Groups:  respGroups,
-				Error:   fmt.Sprintf("Only found %d of 4 groups. Try rephrasing or checking your words.", len(groups)),
+				Success:      false,
+				Groups:       toResponseGroups(groups),
+				Alternatives: toAlternatives(ranking),
+				Ambiguous:    toAmbiguous(ranking),
+				Warning:      warning,
+				Error:        fmt.Sprintf("Only found %d of 4 groups. Try rephrasing or checking your words.", len(groups)),
 			})
 			return
 		}
 
 		respondJSON(w, SolveResponse{
 			Success: false,
+			Warning: warning,
 			Error:   fmt.Sprintf("Solver failed: %v. Make sure you entered exactly 16 valid words.", err),
 		})
 		return
 	}
 
-	// Convert to response format
+	respondJSON(w, SolveResponse{
+		Success:      true,
+		Groups:       toResponseGroups(ranking.Partitions[0].Groups),
+		Alternatives: toAlternatives(ranking),
+		Ambiguous:    toAmbiguous(ranking),
+		Warning:      warning,
+	})
+}
+
+// aiWarning explains an AI failure to the user, telling settings problems
+// apart from ones that pass. A rate-limited response carries the
+// provider's Retry-After on to the client.
+func aiWarning(w http.ResponseWriter, err error) string {
+	if err == nil {
+		return ""
+	}
+	log.Printf("AI analysis failed: %v", err)
+
+	var apiErr *ai.APIError
+	switch {
+	case errors.Is(err, ai.ErrAuth):
+		return "The AI provider rejected the API key, so these results come from pattern matching only."
+	case errors.Is(err, ai.ErrQuota):
+		return "The AI provider's quota is used up, so these results come from pattern matching only."
+	case errors.Is(err, ai.ErrRateLimited):
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(apiErr.RetryAfter.Seconds()))))
+		}
+		return "The AI provider is busy (rate limited); try again shortly for AI results."
+	case errors.Is(err, ai.ErrUnavailable):
+		return "The AI provider is unavailable right now; try again shortly for AI results."
+	case errors.Is(err, ai.ErrBadRequest):
+		return "The AI provider rejected the request; check the AI model and settings."
+	default:
+		return "AI analysis failed, so these results come from pattern matching only."
+	}
+}
+
+// newSolver uses the first AI provider configured in the environment (a
+// local model, then Gemini, Claude and OpenAI), or with AI_ENSEMBLE=true
+// asks all of them and merges their answers by vote. Without one it falls
+// back to pattern matching; AI options come from the environment too.
+func newSolver() (*solver.Solver, error) {
+	ensemble := false
+	if value := os.Getenv("AI_ENSEMBLE"); value != "" {
+		var err error
+		if ensemble, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid AI_ENSEMBLE %q: %w", value, err)
+		}
+	}
+
+	var providers []solver.NamedProvider
+	var labels []string
+	add := func(name, label, prefix string, create func(ai.Options) ai.Provider) error {
+		if len(providers) > 0 && !ensemble {
+			return nil
+		}
+		opts, err := ai.EnvOptions(prefix)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, solver.NamedProvider{Name: name, Provider: create(opts)})
+		labels = append(labels, label)
+		return nil
+	}
+
+	if localURL := os.Getenv("LOCAL_AI_URL"); localURL != "" {
+		if err := add("local", "local model at "+localURL, "LOCAL_AI", func(opts ai.Options) ai.Provider {
+			return ai.NewLocalProviderWithOptions(opts)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if apiKey := os.Getenv("GEMINI_API_KEY"); apiKey != "" {
+		if err := add("gemini", "Gemini AI", "GEMINI", func(opts ai.Options) ai.Provider {
+			return ai.NewGeminiProviderWithOptions(apiKey, opts)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
+		if err := add("claude", "Claude", "ANTHROPIC", func(opts ai.Options) ai.Provider {
+			return ai.NewClaudeProviderWithOptions(apiKey, opts)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
+		if err := add("openai", "OpenAI", "OPENAI", func(opts ai.Options) ai.Provider {
+			return ai.NewOpenAIProviderWithOptions(apiKey, opts)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case len(providers) == 0:
+		log.Printf("No AI configured, using pattern matching")
+		return solver.New(), nil
+	case ensemble && len(providers) > 1:
+		log.Printf("Merging answers by vote from %s", strings.Join(labels, ", "))
+		return solver.NewEnsemble(providers...), nil
+	default:
+		log.Printf("Using %s", labels[0])
+		return solver.NewWithProvider(providers[0].Provider), nil
+	}
+}
+
+// toResponseGroups converts solver groups to the response format
+func toResponseGroups(groups []solver.Group) []Group {
 	respGroups := make([]Group, len(groups))
 	for i, grp := range groups {
 		respGroups[i] = Group{
@@ -246,13 +419,34 @@ func handleSolve(w http.ResponseWriter, r *http.Request) {
 			Theme:       grp.Theme,
 			Explanation: grp.Explanation,
 			Confidence:  grp.Confidence,
+			Difficulty:  grp.Difficulty.String(),
 		}
 	}
+	return respGroups
+}
+
+// toAlternatives converts the runner-up partitions to the response format
+func toAlternatives(ranking *solver.Ranking) []Alternative {
+	var alternatives []Alternative
+	for _, p := range ranking.Partitions[1:] {
+		alternatives = append(alternatives, Alternative{
+			Groups: toResponseGroups(p.Groups),
+			Score:  p.Score,
+		})
+	}
+	return alternatives
+}
 
-	respondJSON(w, SolveResponse{
-		Success: true,
-		Groups:  respGroups,
-	})
+// toAmbiguous lists the words that fit more than one candidate group
+func toAmbiguous(ranking *solver.Ranking) []AmbiguousWord {
+	var ambiguous []AmbiguousWord
+	for _, fit := range ranking.Ambiguous() {
+		ambiguous = append(ambiguous, AmbiguousWord{
+			Word:   fit.Word,
+			Themes: fit.Themes(),
+		})
+	}
+	return ambiguous
 }
 
 func handleHealth(w http.ResponseWriter, _ *http.Request) {
@@ -1,9 +1,24 @@
 package ai
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/replies")
+
 func TestParseJSONResponse(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -105,3 +120,576 @@ func TestParseJSONResponse(t *testing.T) {
 		})
 	}
 }
+
+// TestParseJSONResponseGolden parses each bad reply in testdata/replies
+// and compares the groups with its .golden file
+func TestParseJSONResponseGolden(t *testing.T) {
+	replies, err := filepath.Glob(filepath.Join("testdata", "replies", "*.txt"))
+	if err != nil || len(replies) == 0 {
+		t.Fatalf("no replies in testdata: %v", err)
+	}
+
+	for _, reply := range replies {
+		name := strings.TrimSuffix(filepath.Base(reply), ".txt")
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(reply)
+			if err != nil {
+				t.Fatal(err)
+			}
+			groups, err := parseJSONResponse(string(content))
+			if err != nil {
+				t.Fatalf("parseJSONResponse() error = %v", err)
+			}
+
+			golden := strings.TrimSuffix(reply, ".txt") + ".golden"
+			if *update {
+				encoded, _ := json.MarshalIndent(groups, "", "  ")
+				if err := os.WriteFile(golden, append(encoded, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			data, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing golden file (run with -update): %v", err)
+			}
+			var want []SuggestedGroup
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(groups, want) {
+				t.Errorf("parseJSONResponse() = %+v, want %+v", groups, want)
+			}
+		})
+	}
+}
+
+func TestParseConfidence(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want float64
+	}{
+		{`0.8`, 0.8},
+		{`"0.8"`, 0.8},
+		{`"85%"`, 0.85},
+		{`" 85 % "`, 0.85},
+		{`92`, 0.92},
+		{`"High"`, 0.85},
+		{`-0.5`, 0},
+		{`null`, 0},
+		{``, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := parseConfidence(json.RawMessage(tt.raw))
+		if err != nil {
+			t.Errorf("parseConfidence(%s) error = %v", tt.raw, err)
+			continue
+		}
+		if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("parseConfidence(%s) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+
+	if _, err := parseConfidence(json.RawMessage(`"certain-ish"`)); err == nil {
+		t.Error("expected an error for an unreadable confidence")
+	}
+}
+
+func TestBuildPromptWordCount(t *testing.T) {
+	prompt := buildPrompt([]string{"A", "B", "C", "D", "E", "F", "G", "H"})
+	if !strings.Contains(prompt, "exactly 2 groups of 4 words from this list of 8 words") {
+		t.Errorf("prompt does not reflect the reduced word list:\n%s", prompt)
+	}
+}
+
+func TestFeedbackInPrompt(t *testing.T) {
+	reply := `[{"words": ["BASS", "TROUT", "PERCH", "SOLE"], "theme": "Fish", "confidence": 0.9}]`
+
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		prompts = append(prompts, req.Messages[len(req.Messages)-1].Content)
+		var resp openAIResponse
+		resp.Choices = append(resp.Choices, struct {
+			Message openAIMessage `json:"message"`
+		}{Message: openAIMessage{Role: "assistant", Content: reply}})
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	words := []string{"BASS", "TROUT", "PERCH", "SOLE"}
+	problems := []string{`"SOLES" is not in the puzzle`, "only 3 of the 4 groups had 4 words from the list"}
+	p := NewLocalProviderWithOptions(Options{BaseURL: server.URL, TextOnly: true})
+	if _, err := p.AnalyzeWords(context.Background(), words); err != nil {
+		t.Fatalf("AnalyzeWords() error = %v", err)
+	}
+	if _, err := p.AnalyzeWithFeedback(context.Background(), words, problems); err != nil {
+		t.Fatalf("AnalyzeWithFeedback() error = %v", err)
+	}
+	if _, err := p.PickGroupWithFeedback(context.Background(), words, problems); err != nil {
+		t.Fatalf("PickGroupWithFeedback() error = %v", err)
+	}
+
+	if prompts[0] != buildPrompt(words) {
+		t.Errorf("expected the first prompt without feedback, got %q", prompts[0])
+	}
+	for _, prompt := range prompts[1:] {
+		for _, problem := range problems {
+			if !strings.Contains(prompt, problem) {
+				t.Errorf("expected the retry prompt to mention %q, got %q", problem, prompt)
+			}
+		}
+	}
+}
+
+func TestParsePickResponse(t *testing.T) {
+	group, err := parsePickResponse(`[
+		{"words": ["BASS", "TROUT", "PERCH", "SOLE"], "theme": "Fish", "confidence": 0.7},
+		{"words": ["CLUB", "DIAMOND", "HEART", "SPADE"], "theme": "Card Suits", "confidence": 0.9}
+	]`)
+	if err != nil {
+		t.Fatalf("parsePickResponse() error = %v", err)
+	}
+	if group.Theme != "Card Suits" {
+		t.Errorf("expected most confident group, got %q", group.Theme)
+	}
+}
+
+func TestLocalProvider(t *testing.T) {
+	reply := `[{"words": ["BASS", "TROUT", "PERCH", "SOLE"], "theme": "Fish", "confidence": 0.9}]`
+
+	var got openAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("expected no Authorization header, got %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		var resp openAIResponse
+		resp.Choices = append(resp.Choices, struct {
+			Message openAIMessage `json:"message"`
+		}{Message: openAIMessage{Role: "assistant", Content: reply}})
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	p := NewLocalProvider(server.URL+"/v1/", "qwen2.5")
+	groups, err := p.AnalyzeWords(context.Background(), []string{"BASS", "TROUT", "PERCH", "SOLE"})
+	if err != nil {
+		t.Fatalf("AnalyzeWords() error = %v", err)
+	}
+	if len(groups) != 1 || groups[0].Theme != "Fish" {
+		t.Errorf("expected the server's group, got %+v", groups)
+	}
+	if got.Model != "qwen2.5" {
+		t.Errorf("expected model qwen2.5 in the request, got %q", got.Model)
+	}
+
+	defaults := NewLocalProvider("", "")
+	if defaults.options.BaseURL != DefaultLocalBaseURL || defaults.options.Model != DefaultLocalModel {
+		t.Errorf("expected defaults, got %q and %q", defaults.options.BaseURL, defaults.options.Model)
+	}
+}
+
+func TestProviderOptions(t *testing.T) {
+	reply := `[{"words": ["BASS", "TROUT", "PERCH", "SOLE"], "theme": "Fish", "confidence": 0.9}]`
+	temperature := 0.2
+	opts := Options{
+		Model:       "custom-model",
+		Temperature: &temperature,
+		MaxTokens:   512,
+		Headers:     map[string]string{"X-Proxy-Token": "secret"},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		response any
+		provider func(baseURL string) Provider
+	}{
+		{
+			name: "openai",
+			path: "/chat/completions",
+			response: map[string]any{"choices": []any{
+				map[string]any{"message": map[string]any{"role": "assistant", "content": reply}},
+			}},
+			provider: func(baseURL string) Provider {
+				o := opts
+				o.BaseURL = baseURL
+				return NewOpenAIProviderWithOptions("key", o)
+			},
+		},
+		{
+			name:     "claude",
+			path:     "/messages",
+			response: map[string]any{"content": []any{map[string]any{"text": reply}}},
+			provider: func(baseURL string) Provider {
+				o := opts
+				o.BaseURL = baseURL
+				return NewClaudeProviderWithOptions("key", o)
+			},
+		},
+		{
+			name: "gemini",
+			path: "/models/custom-model:generateContent",
+			response: map[string]any{"candidates": []any{
+				map[string]any{"content": map[string]any{"parts": []any{map[string]any{"text": reply}}}},
+			}},
+			provider: func(baseURL string) Provider {
+				o := opts
+				o.BaseURL = baseURL
+				return NewGeminiProviderWithOptions("key", o)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					t.Errorf("expected path %q, got %q", tt.path, r.URL.Path)
+				}
+				if got := r.Header.Get("X-Proxy-Token"); got != "secret" {
+					t.Errorf("expected the custom header, got %q", got)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				_ = json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer server.Close()
+
+			groups, err := tt.provider(server.URL+"/").AnalyzeWords(context.Background(), []string{"BASS", "TROUT", "PERCH", "SOLE"})
+			if err != nil {
+				t.Fatalf("AnalyzeWords() error = %v", err)
+			}
+			if len(groups) != 1 {
+				t.Errorf("expected one group, got %+v", groups)
+			}
+
+			encoded, _ := json.Marshal(body)
+			for _, want := range []string{"0.2", "512"} {
+				if !strings.Contains(string(encoded), want) {
+					t.Errorf("expected %s in the request, got %s", want, encoded)
+				}
+			}
+			if tt.name != "gemini" && body["model"] != "custom-model" {
+				t.Errorf("expected the custom model, got %v", body["model"])
+			}
+		})
+	}
+}
+
+func TestStructuredOutput(t *testing.T) {
+	structured := `{"groups": [{"words": ["BASS", "TROUT", "PERCH", "SOLE"], "theme": "Fish", "explanation": "", "confidence": 0.9, "difficulty": "yellow"}]}`
+
+	tests := []struct {
+		name     string
+		field    string // Request field that carries the schema
+		response any
+		provider func(baseURL string) Provider
+	}{
+		{
+			name:  "openai",
+			field: `"response_format":{"json_schema"`,
+			response: map[string]any{"choices": []any{
+				map[string]any{"message": map[string]any{"role": "assistant", "content": structured}},
+			}},
+			provider: func(baseURL string) Provider {
+				return NewOpenAIProviderWithOptions("key", Options{BaseURL: baseURL})
+			},
+		},
+		{
+			name:  "claude",
+			field: `"tool_choice":{"name":"submit_groups","type":"tool"}`,
+			response: map[string]any{"content": []any{
+				map[string]any{"type": "tool_use", "name": structuredToolName, "input": json.RawMessage(structured)},
+			}},
+			provider: func(baseURL string) Provider {
+				return NewClaudeProviderWithOptions("key", Options{BaseURL: baseURL})
+			},
+		},
+		{
+			name:  "gemini",
+			field: `"responseMimeType":"application/json","responseSchema":{"properties"`,
+			response: map[string]any{"candidates": []any{
+				map[string]any{"content": map[string]any{"parts": []any{map[string]any{"text": structured}}}},
+			}},
+			provider: func(baseURL string) Provider {
+				return NewGeminiProviderWithOptions("key", Options{BaseURL: baseURL})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				_ = json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer server.Close()
+
+			group, err := tt.provider(server.URL).(GroupPicker).PickGroup(context.Background(), []string{"BASS", "TROUT", "PERCH", "SOLE"})
+			if err != nil {
+				t.Fatalf("PickGroup() error = %v", err)
+			}
+			if group.Theme != "Fish" || group.Difficulty != "yellow" {
+				t.Errorf("expected the structured group, got %+v", group)
+			}
+
+			encoded, _ := json.Marshal(body)
+			if !strings.Contains(string(encoded), tt.field) {
+				t.Errorf("expected %s in the request, got %s", tt.field, encoded)
+			}
+		})
+	}
+}
+
+func TestStructuredOutputFallback(t *testing.T) {
+	reply := `[{"words": ["BASS", "TROUT", "PERCH", "SOLE"], "theme": "Fish", "confidence": 0.9}]`
+
+	var requests, structuredRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		requests.Add(1)
+		if req.ResponseFormat != nil {
+			structuredRequests.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"message": "response_format is not supported"}}`))
+			return
+		}
+		var resp openAIResponse
+		resp.Choices = append(resp.Choices, struct {
+			Message openAIMessage `json:"message"`
+		}{Message: openAIMessage{Role: "assistant", Content: reply}})
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	p := NewLocalProvider(server.URL, "")
+	for i := 0; i < 2; i++ {
+		groups, err := p.AnalyzeWords(context.Background(), []string{"BASS", "TROUT", "PERCH", "SOLE"})
+		if err != nil {
+			t.Fatalf("AnalyzeWords() error = %v", err)
+		}
+		if len(groups) != 1 || groups[0].Theme != "Fish" {
+			t.Errorf("expected the text reply's group, got %+v", groups)
+		}
+	}
+	if structuredRequests.Load() != 1 || requests.Load() != 3 {
+		t.Errorf("expected one rejected structured request then text only, got %d of %d", structuredRequests.Load(), requests.Load())
+	}
+
+	textOnly := NewLocalProviderWithOptions(Options{BaseURL: server.URL, TextOnly: true})
+	if _, err := textOnly.AnalyzeWords(context.Background(), []string{"BASS", "TROUT", "PERCH", "SOLE"}); err != nil {
+		t.Fatalf("AnalyzeWords() error = %v", err)
+	}
+	if structuredRequests.Load() != 1 {
+		t.Error("expected TextOnly to skip the structured request")
+	}
+}
+
+func TestStructuredOutputLatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantLatch bool
+	}{
+		{"schema rejected", newAPIError("OpenAI", 400, 0, "Invalid schema for response_format 'groups'"), true},
+		{"tools unsupported", newAPIError("Local AI", 422, 0, "This model does not support tool use"), true},
+		{"wrong model", newAPIError("OpenAI", 404, 0, "The model 'gpt-9' does not exist"), false},
+		{"error in body", newAPIError("Gemini", 0, 0, "Invalid responseSchema"), false},
+		{"unrelated parameter", newAPIError("OpenAI", 400, 0, "temperature is not supported with this model"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var textOnly atomic.Bool
+			var structuredCalls, textCalls int
+			complete := func(ctx context.Context, prompt string, structured bool) (string, error) {
+				if structured {
+					structuredCalls++
+					return "", tt.err
+				}
+				textCalls++
+				return "[]", nil
+			}
+
+			for i := 0; i < 2; i++ {
+				if _, err := completeStructured(context.Background(), &textOnly, "prompt", complete); err != nil {
+					t.Fatalf("completeStructured() error = %v", err)
+				}
+			}
+			if textOnly.Load() != tt.wantLatch {
+				t.Errorf("textOnly = %v, want %v", textOnly.Load(), tt.wantLatch)
+			}
+			wantStructured := 2
+			if tt.wantLatch {
+				wantStructured = 1
+			}
+			if structuredCalls != wantStructured || textCalls != 2 {
+				t.Errorf("got %d structured and %d text calls, want %d and 2", structuredCalls, textCalls, wantStructured)
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = 500 * time.Millisecond }()
+
+	ok := `{"choices": [{"message": {"role": "assistant", "content": "[{\"words\": [\"BASS\", \"TROUT\", \"PERCH\", \"SOLE\"], \"theme\": \"Fish\"}]"}}]}`
+
+	tests := []struct {
+		name         string
+		status       int    // Returned by every request but the last scripted one
+		body         string // Error body
+		retryAfter   string
+		failures     int // Failing requests before success
+		maxRetries   int
+		wantKind     error // nil for success
+		wantRequests int
+	}{
+		{name: "server errors are retried", status: 503, body: "overloaded", failures: 2, wantRequests: 3},
+		{name: "rate limit is retried", status: 429, body: `{"error": {"message": "slow down"}}`, retryAfter: "0", failures: 1, wantRequests: 2},
+		{name: "retries run out", status: 500, body: "{}", failures: 10, wantKind: ErrUnavailable, wantRequests: 4},
+		{name: "retries disabled", status: 529, body: "{}", failures: 10, maxRetries: -1, wantKind: ErrUnavailable, wantRequests: 1},
+		{name: "auth is not retried", status: 401, body: `{"error": {"message": "invalid api key"}}`, failures: 10, wantKind: ErrAuth, wantRequests: 1},
+		{name: "quota is not retried", status: 429, body: `{"error": {"message": "You exceeded your current quota, please check your plan and billing details"}}`, failures: 10, wantKind: ErrQuota, wantRequests: 1},
+		{name: "long Retry-After is not waited out", status: 429, body: "{}", retryAfter: "3600", failures: 10, wantKind: ErrRateLimited, wantRequests: 1},
+		{name: "bad request is not retried", status: 404, body: `{"error": "model not found"}`, failures: 10, maxRetries: 5, wantKind: ErrBadRequest, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(requests.Add(1)) <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(tt.body))
+					return
+				}
+				_, _ = w.Write([]byte(ok))
+			}))
+			defer server.Close()
+
+			p := NewOpenAIProviderWithOptions("key", Options{BaseURL: server.URL, MaxRetries: tt.maxRetries, TextOnly: true})
+			_, err := p.AnalyzeWords(context.Background(), []string{"BASS", "TROUT", "PERCH", "SOLE"})
+			if tt.wantKind == nil && err != nil {
+				t.Fatalf("AnalyzeWords() error = %v", err)
+			}
+			if tt.wantKind != nil && !errors.Is(err, tt.wantKind) {
+				t.Errorf("AnalyzeWords() error = %v, want %v", err, tt.wantKind)
+			}
+			if got := int(requests.Load()); got != tt.wantRequests {
+				t.Errorf("expected %d requests, got %d", tt.wantRequests, got)
+			}
+		})
+	}
+}
+
+func TestRetryAttemptTimeout(t *testing.T) {
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = 500 * time.Millisecond }()
+
+	ok := `{"choices": [{"message": {"role": "assistant", "content": "[{\"words\": [\"BASS\", \"TROUT\", \"PERCH\", \"SOLE\"], \"theme\": \"Fish\"}]"}}]}`
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Outlast the attempt's timeout
+			<-release
+			return
+		}
+		_, _ = w.Write([]byte(ok))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	p := NewOpenAIProviderWithOptions("key", Options{BaseURL: server.URL, Timeout: 50 * time.Millisecond, TextOnly: true})
+	if _, err := p.AnalyzeWords(context.Background(), []string{"BASS", "TROUT", "PERCH", "SOLE"}); err != nil {
+		t.Fatalf("AnalyzeWords() error = %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("expected the timed out attempt to be retried, got %d requests", got)
+	}
+}
+
+func TestErrorMessageTruncation(t *testing.T) {
+	body := strings.Repeat("a", 199) + strings.Repeat("é", 10)
+	got := errorMessage([]byte(body), "500 Internal Server Error")
+	if !utf8.ValidString(got) {
+		t.Errorf("errorMessage() cut a rune: %q", got)
+	}
+	if want := strings.Repeat("a", 199) + "..."; got != want {
+		t.Errorf("errorMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEnvOptions(t *testing.T) {
+	t.Setenv("GEMINI_MODEL", "gemini-2.5-pro")
+	t.Setenv("GEMINI_URL", "https://proxy.example.com/v1beta")
+	t.Setenv("AI_TEMPERATURE", "0.3")
+	t.Setenv("AI_MAX_TOKENS", "2048")
+	t.Setenv("AI_TIMEOUT", "90s")
+	t.Setenv("AI_HEADERS", "X-One: 1; X-Two: two words")
+
+	opts, err := EnvOptions("GEMINI")
+	if err != nil {
+		t.Fatalf("EnvOptions() error = %v", err)
+	}
+	if opts.Model != "gemini-2.5-pro" || opts.BaseURL != "https://proxy.example.com/v1beta" {
+		t.Errorf("unexpected model or URL: %+v", opts)
+	}
+	if opts.Temperature == nil || *opts.Temperature != 0.3 || opts.MaxTokens != 2048 || opts.Timeout.Seconds() != 90 {
+		t.Errorf("unexpected parameters: %+v", opts)
+	}
+	if opts.Headers["X-One"] != "1" || opts.Headers["X-Two"] != "two words" {
+		t.Errorf("unexpected headers: %v", opts.Headers)
+	}
+
+	t.Setenv("AI_MAX_TOKENS", "lots")
+	if _, err := EnvOptions("GEMINI"); err == nil {
+		t.Error("expected an error for an invalid AI_MAX_TOKENS")
+	}
+}
@@ -0,0 +1,284 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// rawGroup is a group as models actually write it, before the confidence
+// is coerced to a number
+type rawGroup struct {
+	Words       []string        `json:"words"`
+	Theme       string          `json:"theme"`
+	Explanation string          `json:"explanation"`
+	Confidence  json.RawMessage `json:"confidence"`
+	Difficulty  string          `json:"difficulty"`
+}
+
+// groupListKeys are the object keys a wrapped group list is looked for
+// under, before falling back to any array of objects
+var groupListKeys = []string{"groups", "answer", "answers", "solution", "result", "results"}
+
+// confidenceWords maps confidence given in words to a number
+var confidenceWords = map[string]float64{
+	"very high": 0.95,
+	"high":      0.85,
+	"medium":    0.6,
+	"moderate":  0.6,
+	"low":       0.35,
+	"very low":  0.15,
+}
+
+// extractGroups finds the group list in a free-text reply: the first
+// balanced JSON array or object that decodes to groups, after repairing
+// common syntax slips. Prose, markdown fences, {"groups": [...]} wrappers,
+// single group objects and replies cut off mid-array are all tolerated.
+func extractGroups(content string) ([]SuggestedGroup, error) {
+	content = strings.NewReplacer("“", `"`, "”", `"`).Replace(content)
+
+	candidates := jsonCandidates(content)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no JSON array or object found")
+	}
+
+	var firstErr error
+	for _, candidate := range candidates {
+		groups, err := decodeGroups(repairJSON(candidate))
+		if err == nil {
+			return groups, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// jsonCandidates returns the outermost balanced arrays and objects in
+// content, in order. A reply truncated inside an array also yields the
+// array closed after its last complete element.
+func jsonCandidates(content string) []string {
+	var candidates []string
+	for i := 0; i < len(content); i++ {
+		if content[i] != '[' && content[i] != '{' {
+			continue
+		}
+		end, lastElement := balancedEnd(content, i)
+		if end > 0 {
+			candidates = append(candidates, content[i:end])
+			i = end - 1
+			continue
+		}
+		if lastElement > 0 {
+			candidates = append(candidates, content[i:lastElement]+"]")
+		}
+	}
+	return candidates
+}
+
+// balancedEnd returns the index just past the bracket closing the one at
+// start, or -1 if it is never closed or closed by the wrong bracket. For
+// an unclosed array it also returns the index just past its last complete
+// object element, or -1 if there is none.
+func balancedEnd(content string, start int) (int, int) {
+	var stack []byte
+	inString, escaped := false, false
+	lastElement := -1
+
+	for i := start; i < len(content); i++ {
+		c := content[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			stack = append(stack, c)
+		case ']', '}':
+			open := byte('[')
+			if c == '}' {
+				open = '{'
+			}
+			if len(stack) == 0 || stack[len(stack)-1] != open {
+				return -1, -1
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i + 1, -1
+			}
+			if len(stack) == 1 && content[start] == '[' && c == '}' {
+				lastElement = i + 1
+			}
+		}
+	}
+	return -1, lastElement
+}
+
+// repairJSON fixes the slips models make most: trailing commas, missing
+// commas between objects and // comments. Text inside strings is untouched.
+func repairJSON(s string) string {
+	var b strings.Builder
+	inString, escaped := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			b.WriteByte(c)
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(s) && s[i+1] == '/':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			i--
+			continue
+		case c == ',':
+			if next := nextNonSpace(s, i+1); next == ']' || next == '}' {
+				continue
+			}
+		case c == '}':
+			if nextNonSpace(s, i+1) == '{' {
+				b.WriteString("},")
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// nextNonSpace returns the first byte from i on that is not whitespace
+func nextNonSpace(s string, i int) byte {
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		return s[i]
+	}
+	return 0
+}
+
+// decodeGroups decodes an array of groups, a single group, or an object
+// wrapping the group list
+func decodeGroups(data string) ([]SuggestedGroup, error) {
+	var list []rawGroup
+	if err := json.Unmarshal([]byte(data), &list); err == nil {
+		return coerceGroups(list)
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &object); err != nil {
+		return nil, err
+	}
+	object = lowerKeys(object)
+
+	for _, key := range groupListKeys {
+		if value, ok := object[key]; ok {
+			return decodeGroups(string(value))
+		}
+	}
+
+	if _, ok := object["words"]; ok {
+		var group rawGroup
+		if err := json.Unmarshal([]byte(data), &group); err != nil {
+			return nil, err
+		}
+		return coerceGroups([]rawGroup{group})
+	}
+	for _, value := range object {
+		if err := json.Unmarshal(value, &list); err == nil && len(list) > 0 {
+			return coerceGroups(list)
+		}
+	}
+	return nil, fmt.Errorf("object has no group list")
+}
+
+// lowerKeys lower-cases the keys of a decoded object
+func lowerKeys(object map[string]json.RawMessage) map[string]json.RawMessage {
+	lowered := make(map[string]json.RawMessage, len(object))
+	for key, value := range object {
+		lowered[strings.ToLower(key)] = value
+	}
+	return lowered
+}
+
+// coerceGroups converts decoded groups, normalizing confidence and difficulty
+func coerceGroups(raw []rawGroup) ([]SuggestedGroup, error) {
+	groups := make([]SuggestedGroup, len(raw))
+	for i, group := range raw {
+		confidence, err := parseConfidence(group.Confidence)
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %w", i+1, err)
+		}
+		groups[i] = SuggestedGroup{
+			Words:       group.Words,
+			Theme:       group.Theme,
+			Explanation: group.Explanation,
+			Confidence:  confidence,
+			Difficulty:  strings.ToLower(strings.TrimSpace(group.Difficulty)),
+		}
+	}
+	return groups, nil
+}
+
+// parseConfidence reads a confidence given as a number, a numeric string,
+// a percentage ("85%" or 85) or a word ("high"), clamped to 0-1. A missing
+// confidence is 0.
+func parseConfidence(raw json.RawMessage) (float64, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	var value float64
+	if err := json.Unmarshal(raw, &value); err != nil {
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return 0, fmt.Errorf("invalid confidence %s", raw)
+		}
+		text = strings.ToLower(strings.TrimSpace(text))
+
+		if word, ok := confidenceWords[text]; ok {
+			return word, nil
+		}
+		percent := strings.HasSuffix(text, "%")
+		value, err = strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(text, "%")), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid confidence %q", text)
+		}
+		if percent {
+			value /= 100
+		}
+	}
+
+	if value > 1 {
+		// Percentages written as plain numbers
+		value /= 100
+	}
+	return math.Max(0, math.Min(1, value)), nil
+}
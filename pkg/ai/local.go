@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+const (
+	// DefaultLocalBaseURL is Ollama's OpenAI-compatible API on its default port
+	DefaultLocalBaseURL = "http://localhost:11434/v1"
+	// DefaultLocalModel is used when no local model is named
+	DefaultLocalModel = "llama3.1"
+)
+
+// LocalProvider implements the Provider interface for self-hosted models
+// behind any OpenAI-compatible chat completions endpoint: Ollama, llama.cpp's
+// server, vLLM, LM Studio or a test stand-in. No API key is needed; servers
+// that want one can be given an Authorization header in the options.
+type LocalProvider struct {
+	options  Options
+	client   *http.Client
+	textOnly atomic.Bool // Set once the server rejects structured output
+}
+
+// NewLocalProvider creates a provider for the server at baseURL (the part
+// before /chat/completions, e.g. http://localhost:11434/v1). Empty
+// arguments fall back to DefaultLocalBaseURL and DefaultLocalModel.
+func NewLocalProvider(baseURL, model string) *LocalProvider {
+	return NewLocalProviderWithOptions(Options{BaseURL: baseURL, Model: model})
+}
+
+// NewLocalProviderWithOptions creates a local provider with custom request
+// parameters as well as its endpoint and model
+func NewLocalProviderWithOptions(opts Options) *LocalProvider {
+	p := &LocalProvider{
+		options: opts.withDefaults(DefaultLocalModel, DefaultLocalBaseURL, 0),
+		client:  &http.Client{},
+	}
+	p.textOnly.Store(opts.TextOnly)
+	return p
+}
+
+// AnalyzeWords uses the local model to find semantic connections between words
+func (p *LocalProvider) AnalyzeWords(ctx context.Context, words []string) ([]SuggestedGroup, error) {
+	return p.AnalyzeWithFeedback(ctx, words, nil)
+}
+
+// AnalyzeWithFeedback asks the local model for the groups again, listing the problems
+// found in its previous answer
+func (p *LocalProvider) AnalyzeWithFeedback(ctx context.Context, words, feedback []string) ([]SuggestedGroup, error) {
+	content, err := p.complete(ctx, buildPrompt(words)+feedbackNote(feedback))
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONResponse(content)
+}
+
+// PickGroup asks the local model for the single group it is most confident about
+func (p *LocalProvider) PickGroup(ctx context.Context, words []string) (SuggestedGroup, error) {
+	return p.PickGroupWithFeedback(ctx, words, nil)
+}
+
+// PickGroupWithFeedback asks the local model for one group again, listing the problems
+// found in its previous answer
+func (p *LocalProvider) PickGroupWithFeedback(ctx context.Context, words, feedback []string) (SuggestedGroup, error) {
+	content, err := p.complete(ctx, buildPickPrompt(words)+feedbackNote(feedback))
+	if err != nil {
+		return SuggestedGroup{}, err
+	}
+	return parsePickResponse(content)
+}
+
+// complete sends a prompt to the local server and returns the reply text,
+// asking for structured output unless the server has rejected it
+func (p *LocalProvider) complete(ctx context.Context, prompt string) (string, error) {
+	return completeStructured(ctx, &p.textOnly, prompt, func(ctx context.Context, prompt string, structured bool) (string, error) {
+		return chatCompletion(ctx, p.client, p.options, "", prompt, "local model", structured)
+	})
+}
@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options configures a provider. Zero values keep the provider's defaults.
+type Options struct {
+	Model       string            // e.g. "gpt-4o", "claude-sonnet-4-5", "gemini-2.5-pro"
+	BaseURL     string            // API root, before the provider's endpoint path
+	Temperature *float64          // nil leaves the service's default
+	MaxTokens   int               // Reply length limit
+	Timeout     time.Duration     // Bounds each attempt; the caller's deadline still applies
+	MaxRetries  int               // Retries of transient failures; 0 is DefaultMaxRetries, negative is none
+	Headers     map[string]string // Extra request headers, e.g. for a proxy
+	TextOnly    bool              // Skip native structured output and parse the reply text
+}
+
+// withDefaults fills in the fields a provider needs
+func (o Options) withDefaults(model, baseURL string, maxTokens int) Options {
+	if o.Model == "" {
+		o.Model = model
+	}
+	if o.BaseURL == "" {
+		o.BaseURL = baseURL
+	}
+	o.BaseURL = strings.TrimRight(o.BaseURL, "/")
+	if o.MaxTokens <= 0 {
+		o.MaxTokens = maxTokens
+	}
+	return o
+}
+
+// withTimeout bounds a request by the configured timeout, or by
+// defaultAITimeout when neither it nor the caller sets a deadline
+func (o Options) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.Timeout > 0 {
+		return context.WithTimeout(ctx, o.Timeout)
+	}
+	return withDefaultTimeout(ctx)
+}
+
+// setHeaders adds the custom headers, which may override the provider's own
+func (o Options) setHeaders(req *http.Request) {
+	for name, value := range o.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// EnvOptions reads options from the environment: <PREFIX>_MODEL and
+// <PREFIX>_URL for the provider (e.g. GEMINI_MODEL, LOCAL_AI_URL), and
+// AI_TEMPERATURE, AI_MAX_TOKENS, AI_TIMEOUT, AI_MAX_RETRIES, AI_HEADERS and
+// AI_TEXT_ONLY shared by all. AI_HEADERS holds "Name: value" pairs separated by semicolons.
+func EnvOptions(prefix string) (Options, error) {
+	opts := Options{
+		Model:   os.Getenv(prefix + "_MODEL"),
+		BaseURL: os.Getenv(prefix + "_URL"),
+	}
+
+	if value := os.Getenv("AI_TEMPERATURE"); value != "" {
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid AI_TEMPERATURE %q: %w", value, err)
+		}
+		opts.Temperature = &temperature
+	}
+	if value := os.Getenv("AI_MAX_TOKENS"); value != "" {
+		maxTokens, err := strconv.Atoi(value)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid AI_MAX_TOKENS %q: %w", value, err)
+		}
+		opts.MaxTokens = maxTokens
+	}
+	if value := os.Getenv("AI_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid AI_TIMEOUT %q: %w", value, err)
+		}
+		opts.Timeout = timeout
+	}
+	if value := os.Getenv("AI_MAX_RETRIES"); value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid AI_MAX_RETRIES %q: %w", value, err)
+		}
+		opts.MaxRetries = retries
+	}
+	if value := os.Getenv("AI_TEXT_ONLY"); value != "" {
+		textOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid AI_TEXT_ONLY %q: %w", value, err)
+		}
+		opts.TextOnly = textOnly
+	}
+	if value := os.Getenv("AI_HEADERS"); value != "" {
+		for _, header := range strings.Split(value, ";") {
+			if strings.TrimSpace(header) == "" {
+				continue
+			}
+			name, val, err := ParseHeader(header)
+			if err != nil {
+				return Options{}, fmt.Errorf("invalid AI_HEADERS: %w", err)
+			}
+			if opts.Headers == nil {
+				opts.Headers = make(map[string]string)
+			}
+			opts.Headers[name] = val
+		}
+	}
+
+	return opts, nil
+}
+
+// ParseHeader splits a "Name: value" header
+func ParseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("header %q must look like \"Name: value\"", header)
+	}
+	return name, strings.TrimSpace(value), nil
+}
@@ -1,20 +1,38 @@
 package ai
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// defaultAITimeout bounds a request when the caller's context has no deadline
 const defaultAITimeout = 60 * time.Second
 
 // Provider defines the AI provider interface
 type Provider interface {
-	AnalyzeWords(words []string) ([]SuggestedGroup, error)
+	AnalyzeWords(ctx context.Context, words []string) ([]SuggestedGroup, error)
+}
+
+// GroupPicker is implemented by providers that can be asked for just the
+// group they are most confident about among the given words
+type GroupPicker interface {
+	PickGroup(ctx context.Context, words []string) (SuggestedGroup, error)
+}
+
+// FeedbackAnalyzer is implemented by providers that can be asked again with
+// the problems found in their previous answer, so they can avoid them
+type FeedbackAnalyzer interface {
+	AnalyzeWithFeedback(ctx context.Context, words, feedback []string) ([]SuggestedGroup, error)
+}
+
+// FeedbackPicker is the GroupPicker counterpart of FeedbackAnalyzer
+type FeedbackPicker interface {
+	PickGroupWithFeedback(ctx context.Context, words, feedback []string) (SuggestedGroup, error)
 }
 
 // SuggestedGroup represents an AI-suggested grouping
@@ -23,60 +41,116 @@ type SuggestedGroup struct {
 	Theme       string
 	Explanation string
 	Confidence  float64
+	Difficulty  string // NYT colour: yellow, green, blue or purple
 }
 
+// Provider defaults, used for any Options field left unset
+const (
+	DefaultOpenAIModel   = "gpt-4o-mini"
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultClaudeModel   = "claude-3-5-haiku-20241022"
+	DefaultClaudeBaseURL = "https://api.anthropic.com/v1"
+	DefaultGeminiModel   = "gemini-2.5-flash" // Current stable Gemini model (as of 2025)
+	DefaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+	// defaultClaudeMaxTokens is required by the Messages API
+	defaultClaudeMaxTokens = 1024
+)
+
 // OpenAIProvider implements the Provider interface using OpenAI's API
 type OpenAIProvider struct {
-	apiKey string
-	model  string
-	client *http.Client
+	apiKey   string
+	options  Options
+	client   *http.Client
+	textOnly atomic.Bool // Set once the service rejects structured output
 }
 
 // NewOpenAIProvider creates a new OpenAI provider
 func NewOpenAIProvider(apiKey string) *OpenAIProvider {
-	return &OpenAIProvider{
-		apiKey: apiKey,
-		model:  "gpt-4o-mini",
-		client: &http.Client{Timeout: defaultAITimeout},
+	return NewOpenAIProviderWithOptions(apiKey, Options{})
+}
+
+// NewOpenAIProviderWithOptions creates an OpenAI provider with a custom
+// model, endpoint or request parameters
+func NewOpenAIProviderWithOptions(apiKey string, opts Options) *OpenAIProvider {
+	p := &OpenAIProvider{
+		apiKey:  apiKey,
+		options: opts.withDefaults(DefaultOpenAIModel, DefaultOpenAIBaseURL, 0),
+		client:  &http.Client{},
 	}
+	p.textOnly.Store(opts.TextOnly)
+	return p
 }
 
 // ClaudeProvider implements the Provider interface using Anthropic's Claude API
 type ClaudeProvider struct {
-	apiKey string
-	model  string
-	client *http.Client
+	apiKey   string
+	options  Options
+	client   *http.Client
+	textOnly atomic.Bool // Set once the service rejects structured output
 }
 
 // NewClaudeProvider creates a new Claude provider
 func NewClaudeProvider(apiKey string) *ClaudeProvider {
-	return &ClaudeProvider{
-		apiKey: apiKey,
-		model:  "claude-3-5-haiku-20241022",
-		client: &http.Client{Timeout: defaultAITimeout},
+	return NewClaudeProviderWithOptions(apiKey, Options{})
+}
+
+// NewClaudeProviderWithOptions creates a Claude provider with a custom
+// model, endpoint or request parameters
+func NewClaudeProviderWithOptions(apiKey string, opts Options) *ClaudeProvider {
+	p := &ClaudeProvider{
+		apiKey:  apiKey,
+		options: opts.withDefaults(DefaultClaudeModel, DefaultClaudeBaseURL, defaultClaudeMaxTokens),
+		client:  &http.Client{},
 	}
+	p.textOnly.Store(opts.TextOnly)
+	return p
 }
 
 // GeminiProvider implements the Provider interface using Google's Gemini API
 type GeminiProvider struct {
-	apiKey string
-	model  string
-	client *http.Client
+	apiKey   string
+	options  Options
+	client   *http.Client
+	textOnly atomic.Bool // Set once the service rejects structured output
 }
 
 // NewGeminiProvider creates a new Gemini provider
 func NewGeminiProvider(apiKey string) *GeminiProvider {
-	return &GeminiProvider{
-		apiKey: apiKey,
-		model:  "gemini-2.5-flash", // Current stable Gemini model (as of 2025)
-		client: &http.Client{Timeout: defaultAITimeout},
+	return NewGeminiProviderWithOptions(apiKey, Options{})
+}
+
+// NewGeminiProviderWithOptions creates a Gemini provider with a custom
+// model, endpoint or request parameters
+func NewGeminiProviderWithOptions(apiKey string, opts Options) *GeminiProvider {
+	p := &GeminiProvider{
+		apiKey:  apiKey,
+		options: opts.withDefaults(DefaultGeminiModel, DefaultGeminiBaseURL, 0),
+		client:  &http.Client{},
 	}
+	p.textOnly.Store(opts.TextOnly)
+	return p
 }
 
 // OpenAI API structures
 type openAIRequest struct {
-	Model    string          `json:"model"`
-	Messages []openAIMessage `json:"messages"`
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string           `json:"type"`
+	JSONSchema openAIJSONSchema `json:"json_schema"`
+}
+
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Strict bool           `json:"strict"`
+	Schema map[string]any `json:"schema"`
 }
 
 type openAIMessage struct {
@@ -95,9 +169,24 @@ type openAIResponse struct {
 
 // Claude API structures
 type claudeRequest struct {
-	Model     string          `json:"model"`
-	MaxTokens int             `json:"max_tokens"`
-	Messages  []claudeMessage `json:"messages"`
+	Model       string          `json:"model"`
+	MaxTokens   int             `json:"max_tokens"`
+	Messages    []claudeMessage `json:"messages"`
+	Temperature *float64        `json:"temperature,omitempty"`
+
+	Tools      []claudeTool      `json:"tools,omitempty"`
+	ToolChoice *claudeToolChoice `json:"tool_choice,omitempty"`
+}
+
+type claudeTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type claudeToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
 }
 
 type claudeMessage struct {
@@ -107,7 +196,9 @@ type claudeMessage struct {
 
 type claudeResponse struct {
 	Content []struct {
-		Text string `json:"text"`
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Input json.RawMessage `json:"input"`
 	} `json:"content"`
 	Error *struct {
 		Message string `json:"message"`
@@ -116,7 +207,15 @@ type claudeResponse struct {
 
 // Gemini API structures
 type geminiRequest struct {
-	Contents []geminiContent `json:"contents"`
+	Contents         []geminiContent         `json:"contents"`
+	GenerationConfig *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature      *float64       `json:"temperature,omitempty"`
+	MaxOutputTokens  int            `json:"maxOutputTokens,omitempty"`
+	ResponseMimeType string         `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]any `json:"responseSchema,omitempty"`
 }
 
 type geminiContent struct {
@@ -139,11 +238,53 @@ type geminiResponse struct {
 }
 
 // AnalyzeWords uses OpenAI to find semantic connections between words
-func (p *OpenAIProvider) AnalyzeWords(words []string) ([]SuggestedGroup, error) {
-	prompt := buildPrompt(words)
+func (p *OpenAIProvider) AnalyzeWords(ctx context.Context, words []string) ([]SuggestedGroup, error) {
+	return p.AnalyzeWithFeedback(ctx, words, nil)
+}
+
+// AnalyzeWithFeedback asks OpenAI for the groups again, listing the problems
+// found in its previous answer
+func (p *OpenAIProvider) AnalyzeWithFeedback(ctx context.Context, words, feedback []string) ([]SuggestedGroup, error) {
+	content, err := p.complete(ctx, buildPrompt(words)+feedbackNote(feedback))
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONResponse(content)
+}
 
+// PickGroup asks OpenAI for the single group it is most confident about
+func (p *OpenAIProvider) PickGroup(ctx context.Context, words []string) (SuggestedGroup, error) {
+	return p.PickGroupWithFeedback(ctx, words, nil)
+}
+
+// PickGroupWithFeedback asks OpenAI for one group again, listing the problems
+// found in its previous answer
+func (p *OpenAIProvider) PickGroupWithFeedback(ctx context.Context, words, feedback []string) (SuggestedGroup, error) {
+	content, err := p.complete(ctx, buildPickPrompt(words)+feedbackNote(feedback))
+	if err != nil {
+		return SuggestedGroup{}, err
+	}
+	return parsePickResponse(content)
+}
+
+// complete sends a prompt to OpenAI and returns the reply text, asking for
+// structured output unless the service has rejected it
+func (p *OpenAIProvider) complete(ctx context.Context, prompt string) (string, error) {
+	return completeStructured(ctx, &p.textOnly, prompt, func(ctx context.Context, prompt string, structured bool) (string, error) {
+		return chatCompletion(ctx, p.client, p.options, p.apiKey, prompt, "OpenAI", structured)
+	})
+}
+
+// chatCompletion sends a prompt to an OpenAI-style chat completions endpoint
+// under opts.BaseURL and returns the reply text. The API key is optional, as
+// local servers usually need none; name identifies the service in errors.
+// Structured requests carry the group list schema as a json_schema
+// response_format.
+func chatCompletion(ctx context.Context, client *http.Client, opts Options, apiKey, prompt, name string, structured bool) (string, error) {
 	reqBody := openAIRequest{
-		Model: p.model,
+		Model:       opts.Model,
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
 		Messages: []openAIMessage{
 			{
 				Role:    "system",
@@ -156,53 +297,91 @@ func (p *OpenAIProvider) AnalyzeWords(words []string) ([]SuggestedGroup, error)
 		},
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if structured {
+		reqBody.ResponseFormat = &openAIResponseFormat{
+			Type: "json_schema",
+			JSONSchema: openAIJSONSchema{
+				Name:   "connections_groups",
+				Strict: true,
+				Schema: groupListSchema(),
+			},
+		}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	resp, err := p.client.Do(req)
+	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := post(ctx, client, opts, name, opts.BaseURL+"/chat/completions", jsonData, func(req *http.Request) {
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return "", err
 	}
 
 	var apiResp openAIResponse
 	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	if apiResp.Error != nil {
-		return nil, fmt.Errorf("openAI API error: %s", apiResp.Error.Message)
+		return "", newAPIError(name, 0, 0, apiResp.Error.Message)
 	}
 
 	if len(apiResp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
+		return "", fmt.Errorf("no response from %s", name)
 	}
 
-	return parseJSONResponse(apiResp.Choices[0].Message.Content)
+	return apiResp.Choices[0].Message.Content, nil
 }
 
 // AnalyzeWords uses Claude to find semantic connections between words
-func (p *ClaudeProvider) AnalyzeWords(words []string) ([]SuggestedGroup, error) {
-	prompt := buildPrompt(words)
+func (p *ClaudeProvider) AnalyzeWords(ctx context.Context, words []string) ([]SuggestedGroup, error) {
+	return p.AnalyzeWithFeedback(ctx, words, nil)
+}
+
+// AnalyzeWithFeedback asks Claude for the groups again, listing the problems
+// found in its previous answer
+func (p *ClaudeProvider) AnalyzeWithFeedback(ctx context.Context, words, feedback []string) ([]SuggestedGroup, error) {
+	content, err := p.complete(ctx, buildPrompt(words)+feedbackNote(feedback))
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONResponse(content)
+}
+
+// PickGroup asks Claude for the single group it is most confident about
+func (p *ClaudeProvider) PickGroup(ctx context.Context, words []string) (SuggestedGroup, error) {
+	return p.PickGroupWithFeedback(ctx, words, nil)
+}
 
+// PickGroupWithFeedback asks Claude for one group again, listing the problems
+// found in its previous answer
+func (p *ClaudeProvider) PickGroupWithFeedback(ctx context.Context, words, feedback []string) (SuggestedGroup, error) {
+	content, err := p.complete(ctx, buildPickPrompt(words)+feedbackNote(feedback))
+	if err != nil {
+		return SuggestedGroup{}, err
+	}
+	return parsePickResponse(content)
+}
+
+// complete sends a prompt to Claude and returns the reply text, asking for
+// structured output unless the service has rejected it
+func (p *ClaudeProvider) complete(ctx context.Context, prompt string) (string, error) {
+	return completeStructured(ctx, &p.textOnly, prompt, p.message)
+}
+
+// message sends a prompt to the Messages API. Structured requests force a
+// call to a tool whose input schema is the group list, and return the
+// tool input as the reply text.
+func (p *ClaudeProvider) message(ctx context.Context, prompt string, structured bool) (string, error) {
 	reqBody := claudeRequest{
-		Model:     p.model,
-		MaxTokens: 1024,
+		Model:       p.options.Model,
+		MaxTokens:   p.options.MaxTokens,
+		Temperature: p.options.Temperature,
 		Messages: []claudeMessage{
 			{
 				Role:    "user",
@@ -211,51 +390,89 @@ func (p *ClaudeProvider) AnalyzeWords(words []string) ([]SuggestedGroup, error)
 		},
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if structured {
+		reqBody.Tools = []claudeTool{{
+			Name:        structuredToolName,
+			Description: "Submit the groups of 4 words found in the puzzle",
+			InputSchema: groupListSchema(),
+		}}
+		reqBody.ToolChoice = &claudeToolChoice{Type: "tool", Name: structuredToolName}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", p.apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
-
-	resp, err := p.client.Do(req)
+	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := post(ctx, p.client, p.options, "Claude", p.options.BaseURL+"/messages", jsonData, func(req *http.Request) {
+		req.Header.Set("x-api-key", p.apiKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return "", err
 	}
 
 	var apiResp claudeResponse
 	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	if apiResp.Error != nil {
-		return nil, fmt.Errorf("claude API error: %s", apiResp.Error.Message)
+		return "", newAPIError("Claude", 0, 0, apiResp.Error.Message)
+	}
+
+	for _, block := range apiResp.Content {
+		if block.Type == "tool_use" && len(block.Input) > 0 {
+			return string(block.Input), nil
+		}
 	}
 
 	if len(apiResp.Content) == 0 {
-		return nil, fmt.Errorf("no response from Claude")
+		return "", fmt.Errorf("no response from Claude")
 	}
 
-	return parseJSONResponse(apiResp.Content[0].Text)
+	return apiResp.Content[0].Text, nil
 }
 
 // AnalyzeWords uses Gemini to find semantic connections between words
-func (p *GeminiProvider) AnalyzeWords(words []string) ([]SuggestedGroup, error) {
-	prompt := buildPrompt(words)
+func (p *GeminiProvider) AnalyzeWords(ctx context.Context, words []string) ([]SuggestedGroup, error) {
+	return p.AnalyzeWithFeedback(ctx, words, nil)
+}
+
+// AnalyzeWithFeedback asks Gemini for the groups again, listing the problems
+// found in its previous answer
+func (p *GeminiProvider) AnalyzeWithFeedback(ctx context.Context, words, feedback []string) ([]SuggestedGroup, error) {
+	content, err := p.complete(ctx, buildPrompt(words)+feedbackNote(feedback))
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONResponse(content)
+}
+
+// PickGroup asks Gemini for the single group it is most confident about
+func (p *GeminiProvider) PickGroup(ctx context.Context, words []string) (SuggestedGroup, error) {
+	return p.PickGroupWithFeedback(ctx, words, nil)
+}
 
+// PickGroupWithFeedback asks Gemini for one group again, listing the problems
+// found in its previous answer
+func (p *GeminiProvider) PickGroupWithFeedback(ctx context.Context, words, feedback []string) (SuggestedGroup, error) {
+	content, err := p.complete(ctx, buildPickPrompt(words)+feedbackNote(feedback))
+	if err != nil {
+		return SuggestedGroup{}, err
+	}
+	return parsePickResponse(content)
+}
+
+// complete sends a prompt to Gemini and returns the reply text, asking for
+// structured output unless the service has rejected it
+func (p *GeminiProvider) complete(ctx context.Context, prompt string) (string, error) {
+	return completeStructured(ctx, &p.textOnly, prompt, p.generate)
+}
+
+// generate sends a prompt to the generateContent endpoint. Structured
+// requests ask for a JSON reply following the group list schema.
+func (p *GeminiProvider) generate(ctx context.Context, prompt string, structured bool) (string, error) {
 	reqBody := geminiRequest{
 		Contents: []geminiContent{
 			{
@@ -268,51 +485,66 @@ func (p *GeminiProvider) AnalyzeWords(words []string) ([]SuggestedGroup, error)
 		},
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	if p.options.Temperature != nil || p.options.MaxTokens > 0 || structured {
+		reqBody.GenerationConfig = &geminiGenerationConfig{
+			Temperature:     p.options.Temperature,
+			MaxOutputTokens: p.options.MaxTokens,
+		}
 	}
-
-	// Gemini API URL - use v1beta for generateContent endpoint
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", p.model, p.apiKey)
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if structured {
+		reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		reqBody.GenerationConfig.ResponseSchema = geminiSchema(groupListSchema())
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := p.client.Do(req)
+	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	body, err := io.ReadAll(resp.Body)
+	// The default base URL is v1beta, which serves the generateContent endpoint
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", p.options.BaseURL, p.options.Model, p.apiKey)
+
+	body, err := post(ctx, p.client, p.options, "Gemini", url, jsonData, func(*http.Request) {})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return "", err
 	}
 
 	var apiResp geminiResponse
 	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	if apiResp.Error != nil {
-		return nil, fmt.Errorf("gemini API error: %s", apiResp.Error.Message)
+		return "", newAPIError("Gemini", 0, 0, apiResp.Error.Message)
 	}
 
 	if len(apiResp.Candidates) == 0 || len(apiResp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response from Gemini")
+		return "", fmt.Errorf("no response from Gemini")
 	}
 
-	return parseJSONResponse(apiResp.Candidates[0].Content.Parts[0].Text)
+	return apiResp.Candidates[0].Content.Parts[0].Text, nil
+}
+
+// withDefaultTimeout applies defaultAITimeout unless the caller set a deadline
+func withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, defaultAITimeout)
+}
+
+// feedbackNote renders the problems found in a previous answer for the end
+// of a prompt
+func feedbackNote(problems []string) string {
+	if len(problems) == 0 {
+		return ""
+	}
+	return "\n\nYour previous answer had these problems, so avoid them this time:\n- " + strings.Join(problems, "\n- ")
 }
 
 // buildPrompt creates the prompt for AI analysis (shared between providers)
 func buildPrompt(words []string) string {
-	return fmt.Sprintf(`Find exactly 4 groups of 4 words from this list of 16 words. Each group should share a common theme or category.
+	return fmt.Sprintf(`Find exactly %d groups of 4 words from this list of %d words. Each group should share a common theme or category.
 
 Words: %s
 
@@ -322,7 +554,8 @@ Return your answer as a JSON array with this exact format:
     "words": ["word1", "word2", "word3", "word4"],
     "theme": "brief theme description",
     "explanation": "why these words belong together",
-    "confidence": 0.95
+    "confidence": 0.95,
+    "difficulty": "yellow"
   }
 ]
 
@@ -331,27 +564,67 @@ Rules:
 - Each group must have exactly 4 words
 - Find creative semantic connections
 - Confidence should be 0.0 to 1.0
-- Return ONLY valid JSON, no other text`, strings.Join(words, ", "))
+- Difficulty is the NYT colour: yellow (most straightforward), green, blue or purple (trickiest, often wordplay)
+- Return ONLY valid JSON, no other text`, len(words)/4, len(words), strings.Join(words, ", "))
 }
 
-// parseJSONResponse is a shared function to parse JSON responses from AI providers
-func parseJSONResponse(content string) ([]SuggestedGroup, error) {
-	content = strings.TrimSpace(content)
-	if strings.HasPrefix(content, "```json") {
-		content = strings.TrimPrefix(content, "```json")
-		content = strings.TrimSuffix(content, "```")
-		content = strings.TrimSpace(content)
-	} else if strings.HasPrefix(content, "```") {
-		content = strings.TrimPrefix(content, "```")
-		content = strings.TrimSuffix(content, "```")
-		content = strings.TrimSpace(content)
+// buildPickPrompt creates the prompt asking for only the most certain group,
+// so a puzzle can be solved one locked-in group at a time
+func buildPickPrompt(words []string) string {
+	return fmt.Sprintf(`These %d words split into %d groups of 4 words that each share a common theme or category. Find the ONE group you are most confident about.
+
+Words: %s
+
+Return your answer as a JSON array containing exactly one group, in this exact format:
+[
+  {
+    "words": ["word1", "word2", "word3", "word4"],
+    "theme": "brief theme description",
+    "explanation": "why these words belong together",
+    "confidence": 0.95,
+    "difficulty": "yellow"
+  }
+]
+
+Rules:
+- The group must have exactly 4 words from the list
+- Watch for red herrings: words that seem to fit more than one group
+- Confidence should be 0.0 to 1.0
+- Difficulty is the NYT colour: yellow (most straightforward), green, blue or purple (trickiest, often wordplay)
+- Return ONLY valid JSON, no other text`, len(words), len(words)/4, strings.Join(words, ", "))
+}
+
+// parsePickResponse parses a single-group reply, taking the most confident
+// group if the model returned more than one
+func parsePickResponse(content string) (SuggestedGroup, error) {
+	groups, err := parseJSONResponse(content)
+	if err != nil {
+		return SuggestedGroup{}, err
 	}
 
-	var groups []SuggestedGroup
-	if err := json.Unmarshal([]byte(content), &groups); err != nil {
-		return nil, fmt.Errorf("failed to parse AI response as JSON: %w\nContent: %s", err, content)
+	best := groups[0]
+	for _, group := range groups[1:] {
+		if group.Confidence > best.Confidence {
+			best = group
+		}
 	}
+	return best, nil
+}
+
+// parseJSONResponse is a shared function to parse JSON responses from AI
+// providers, whether structured {"groups": [...]} replies or free text
+// with the JSON somewhere inside it
+func parseJSONResponse(content string) ([]SuggestedGroup, error) {
+	groups, err := extractGroups(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AI response as JSON: %w\nContent: %s", err, strings.TrimSpace(content))
+	}
+
+	return validGroups(groups)
+}
 
+// validGroups drops groups without exactly 4 words
+func validGroups(groups []SuggestedGroup) ([]SuggestedGroup, error) {
 	// Accept partial results - don't fail if we got fewer than 4 groups
 	// The caller will handle partial results appropriately
 	if len(groups) == 0 {
@@ -359,19 +632,19 @@ func parseJSONResponse(content string) ([]SuggestedGroup, error) {
 	}
 
 	// Validate each group has 4 words
-	validGroups := []SuggestedGroup{}
+	valid := []SuggestedGroup{}
 	for i, group := range groups {
 		if len(group.Words) != 4 {
 			// Skip invalid groups but don't fail entirely
 			fmt.Printf("Warning: group %d has %d words, expected 4 - skipping\n", i+1, len(group.Words))
 			continue
 		}
-		validGroups = append(validGroups, group)
+		valid = append(valid, group)
 	}
 
-	if len(validGroups) == 0 {
+	if len(valid) == 0 {
 		return nil, fmt.Errorf("no valid groups found (all groups had wrong number of words)")
 	}
 
-	return validGroups, nil
+	return valid, nil
 }
@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// structuredToolName is the tool Claude is made to call with its answer
+const structuredToolName = "submit_groups"
+
+// groupListSchema is the JSON schema every provider's structured output
+// mode is asked to follow: {"groups": [{"words": [...], ...}]}. Structured
+// modes want an object at the top level, so the list is wrapped.
+func groupListSchema() map[string]any {
+	group := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"words": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Exactly 4 words from the list",
+			},
+			"theme":       map[string]any{"type": "string", "description": "Brief theme description"},
+			"explanation": map[string]any{"type": "string", "description": "Why these words belong together"},
+			"confidence":  map[string]any{"type": "number", "description": "0.0 to 1.0"},
+			"difficulty": map[string]any{
+				"type":        "string",
+				"enum":        []string{"yellow", "green", "blue", "purple"},
+				"description": "NYT colour, yellow easiest and purple trickiest",
+			},
+		},
+		"required":             []string{"words", "theme", "explanation", "confidence", "difficulty"},
+		"additionalProperties": false,
+	}
+
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"groups": map[string]any{"type": "array", "items": group},
+		},
+		"required":             []string{"groups"},
+		"additionalProperties": false,
+	}
+}
+
+// geminiSchema converts a JSON schema to the OpenAPI subset Gemini's
+// responseSchema accepts: upper-case type names and no additionalProperties
+func geminiSchema(schema map[string]any) map[string]any {
+	converted := make(map[string]any, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "additionalProperties":
+			continue
+		case "type":
+			converted[key] = strings.ToUpper(value.(string))
+		case "items":
+			converted[key] = geminiSchema(value.(map[string]any))
+		case "properties":
+			properties := make(map[string]any)
+			for name, property := range value.(map[string]any) {
+				properties[name] = geminiSchema(property.(map[string]any))
+			}
+			converted[key] = properties
+		default:
+			converted[key] = value
+		}
+	}
+	return converted
+}
+
+// structuredKeywords appear in the errors services give when they reject
+// a structured-output request itself: its response_format, tools or schema
+var structuredKeywords = []string{"response_format", "schema", "tool", "responsemimetype", "response_mime_type", "structured"}
+
+// completeStructured asks for a structured reply, unless the service has
+// already rejected one. A request rejected as invalid is retried as plain
+// text; only when the rejection is about structured output does the
+// provider stay in text mode for good.
+func completeStructured(ctx context.Context, textOnly *atomic.Bool, prompt string, complete func(ctx context.Context, prompt string, structured bool) (string, error)) (string, error) {
+	if !textOnly.Load() {
+		content, err := complete(ctx, prompt, true)
+		if !errors.Is(err, ErrBadRequest) {
+			return content, err
+		}
+		if structuredUnsupported(err) {
+			textOnly.Store(true)
+		}
+	}
+	return complete(ctx, prompt, false)
+}
+
+// structuredUnsupported reports whether err is a 400 or 422 rejecting the
+// structured-output part of a request
+func structuredUnsupported(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || (apiErr.StatusCode != http.StatusBadRequest && apiErr.StatusCode != http.StatusUnprocessableEntity) {
+		return false
+	}
+	message := strings.ToLower(apiErr.Message)
+	for _, keyword := range structuredKeywords {
+		if strings.Contains(message, keyword) {
+			return true
+		}
+	}
+	return false
+}
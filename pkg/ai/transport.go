@@ -0,0 +1,239 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Kinds of API failure, matched with errors.Is against an *APIError
+var (
+	ErrAuth        = errors.New("AI authentication failed") // Bad or missing API key
+	ErrQuota       = errors.New("AI quota exhausted")       // Out of credit; waiting will not help
+	ErrRateLimited = errors.New("AI rate limited")          // Too many requests; retry later
+	ErrBadRequest  = errors.New("AI request rejected")      // Invalid model, parameters or schema
+	ErrUnavailable = errors.New("AI service unavailable")   // Server error or overload
+)
+
+const (
+	// DefaultMaxRetries is how many times a transient failure is retried
+	DefaultMaxRetries = 3
+	// maxRetryAfter is the longest Retry-After a request waits out; a
+	// longer one is reported as a failure straight away
+	maxRetryAfter = time.Minute
+	// maxBackoff caps the exponential backoff between retries
+	maxBackoff = 8 * time.Second
+)
+
+// retryBaseDelay is the first backoff delay, doubled on each retry
+var retryBaseDelay = 500 * time.Millisecond
+
+// APIError is a failure the service reported, either as an HTTP error
+// status or as an error in a successful response
+type APIError struct {
+	Provider   string
+	StatusCode int           // 0 when the error came in a 200 response
+	Kind       error         // ErrAuth, ErrQuota, ErrRateLimited, ErrBadRequest or ErrUnavailable
+	Message    string        // The service's own explanation
+	RetryAfter time.Duration // From the Retry-After header, if any
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s API error (HTTP %d): %s", e.Provider, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s API error: %s", e.Provider, e.Message)
+}
+
+// Is matches the error's kind, so errors.Is(err, ErrRateLimited) works
+func (e *APIError) Is(target error) bool {
+	return e.Kind == target
+}
+
+// Retryable reports whether a failed request is worth repeating: transport
+// failures, rate limits and server errors, but not a cancelled context. A
+// request that only ran out its own Options.Timeout is retried by post
+// while the caller's context is still live.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Kind == ErrRateLimited || apiErr.Kind == ErrUnavailable
+	}
+	return true
+}
+
+// newAPIError classifies a failure by status code and message
+func newAPIError(provider string, status int, retryAfter time.Duration, message string) *APIError {
+	err := &APIError{
+		Provider:   provider,
+		StatusCode: status,
+		Message:    message,
+		RetryAfter: retryAfter,
+	}
+
+	lower := strings.ToLower(message)
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		err.Kind = ErrAuth
+	case status == http.StatusTooManyRequests:
+		// Services report spent credit as 429 too; it only passes if the
+		// service suggests retrying
+		exhausted := strings.Contains(lower, "quota") || strings.Contains(lower, "billing") || strings.Contains(lower, "credit")
+		if exhausted && retryAfter == 0 && !strings.Contains(lower, "retry") {
+			err.Kind = ErrQuota
+		} else {
+			err.Kind = ErrRateLimited
+		}
+	case status == http.StatusRequestTimeout || status >= 500:
+		// Includes Anthropic's 529 overloaded
+		err.Kind = ErrUnavailable
+	default:
+		err.Kind = ErrBadRequest
+	}
+	return err
+}
+
+// post sends a JSON body to url and returns the response body, retrying
+// rate limits, server errors and transport failures with jittered
+// exponential backoff, or after the delay a Retry-After header asks for.
+// Each attempt is bounded by the options' timeout; name identifies the
+// service in errors.
+func post(ctx context.Context, client *http.Client, opts Options, name, url string, body []byte, setHeaders func(*http.Request)) ([]byte, error) {
+	retries := opts.MaxRetries
+	if retries == 0 {
+		retries = DefaultMaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		respBody, retryAfter, err := postOnce(ctx, client, opts, name, url, body, setHeaders)
+		// The attempt's own timeout expiring is transient, unlike the caller's
+		timedOut := errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil
+		if err == nil || attempt >= retries || !(Retryable(err) || timedOut) || ctx.Err() != nil {
+			return respBody, err
+		}
+
+		delay := retryAfter
+		if delay == 0 {
+			delay = backoff(attempt)
+		}
+		if delay > maxRetryAfter {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// postOnce makes a single request, turning error statuses into an *APIError
+func postOnce(ctx context.Context, client *http.Client, opts Options, name, url string, body []byte, setHeaders func(*http.Request)) ([]byte, time.Duration, error) {
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	setHeaders(req)
+	opts.setHeaders(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, retryAfter, newAPIError(name, resp.StatusCode, retryAfter, errorMessage(respBody, resp.Status))
+	}
+	return respBody, 0, nil
+}
+
+// backoff returns the delay before retry number attempt+1: the base delay
+// doubled per attempt, capped, with jitter so parallel callers spread out
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << attempt
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an
+// HTTP date, returning 0 if it is absent or unreadable
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil && when.After(now) {
+		return when.Sub(now)
+	}
+	return 0
+}
+
+// errorMessage pulls the explanation out of an error response body, which
+// services shape as {"error": {"message": ...}} or {"error": "..."}
+func errorMessage(body []byte, status string) string {
+	var shaped struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &shaped); err == nil && len(shaped.Error) > 0 {
+		var detail struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(shaped.Error, &detail); err == nil && detail.Message != "" {
+			return detail.Message
+		}
+		var text string
+		if err := json.Unmarshal(shaped.Error, &text); err == nil && text != "" {
+			return text
+		}
+	}
+
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		return status
+	}
+	if len(text) > 200 {
+		// Cut on a rune boundary so the message stays valid UTF-8
+		cut := 200
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "..."
+	}
+	return text
+}
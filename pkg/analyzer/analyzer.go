@@ -1,36 +1,53 @@
 package analyzer
 
 import (
+	"connections/pkg/lexicon"
+	"connections/pkg/normalize"
+	"sort"
 	"strings"
+	"sync"
+	"unicode"
 )
 
 // Analyzer provides word analysis utilities
 type Analyzer struct {
+	lexicon *lexicon.Lexicon
+
+	anagramOnce  sync.Once
+	anagramIndex map[string][]string // signature -> dictionary words
 }
 
-// New creates a new Analyzer instance
+// New creates a new Analyzer instance backed by the embedded lexicon
 func New() *Analyzer {
-	return &Analyzer{}
+	return NewWithLexicon(lexicon.Default())
+}
+
+// NewWithLexicon creates an Analyzer that uses the given lexicon as its dictionary
+func NewWithLexicon(l *lexicon.Lexicon) *Analyzer {
+	return &Analyzer{lexicon: l}
+}
+
+// Lexicon returns the word categories the analyzer looks words up in
+func (a *Analyzer) Lexicon() *lexicon.Lexicon {
+	return a.lexicon
 }
 
-// HasCommonPrefix checks if words share a common prefix of given length
+// HasCommonPrefix checks if words share a common prefix of given length,
+// counted in letters so accents and spaces do not shift it
 func (a *Analyzer) HasCommonPrefix(words []string, length int) (string, bool) {
-	if len(words) == 0 || length == 0 {
+	if len(words) == 0 || length <= 0 {
 		return "", false
 	}
 
 	prefix := ""
-	if len(words[0]) >= length {
-		prefix = strings.ToLower(words[0][:length])
-	} else {
-		return "", false
-	}
-
-	for _, word := range words[1:] {
-		if len(word) < length {
+	for i, word := range words {
+		letters := lowerLetters(word)
+		if len(letters) < length {
 			return "", false
 		}
-		if strings.ToLower(word[:length]) != prefix {
+		if i == 0 {
+			prefix = string(letters[:length])
+		} else if string(letters[:length]) != prefix {
 			return "", false
 		}
 	}
@@ -38,24 +55,22 @@ func (a *Analyzer) HasCommonPrefix(words []string, length int) (string, bool) {
 	return prefix, true
 }
 
-// HasCommonSuffix checks if words share a common suffix of given length
+// HasCommonSuffix checks if words share a common suffix of given length,
+// counted in letters so accents and spaces do not shift it
 func (a *Analyzer) HasCommonSuffix(words []string, length int) (string, bool) {
-	if len(words) == 0 || length == 0 {
+	if len(words) == 0 || length <= 0 {
 		return "", false
 	}
 
 	suffix := ""
-	if len(words[0]) >= length {
-		suffix = strings.ToLower(words[0][len(words[0])-length:])
-	} else {
-		return "", false
-	}
-
-	for _, word := range words[1:] {
-		if len(word) < length {
+	for i, word := range words {
+		letters := lowerLetters(word)
+		if len(letters) < length {
 			return "", false
 		}
-		if strings.ToLower(word[len(word)-length:]) != suffix {
+		if i == 0 {
+			suffix = string(letters[len(letters)-length:])
+		} else if string(letters[len(letters)-length:]) != suffix {
 			return "", false
 		}
 	}
@@ -63,15 +78,15 @@ func (a *Analyzer) HasCommonSuffix(words []string, length int) (string, bool) {
 	return suffix, true
 }
 
-// AllSameLength checks if all words have the same length
+// AllSameLength checks if all words have the same number of letters
 func (a *Analyzer) AllSameLength(words []string) bool {
 	if len(words) == 0 {
 		return true
 	}
 
-	length := len(words[0])
+	length := normalize.Length(words[0])
 	for _, word := range words[1:] {
-		if len(word) != length {
+		if normalize.Length(word) != length {
 			return false
 		}
 	}
@@ -79,7 +94,86 @@ func (a *Analyzer) AllSameLength(words []string) bool {
 	return true
 }
 
-// ContainsSubstring checks if a word contains a substring (case-insensitive)
+// ContainsSubstring checks if a word's letters contain a substring's,
+// ignoring case, accents, spaces and punctuation
 func (a *Analyzer) ContainsSubstring(word, substring string) bool {
-	return strings.Contains(strings.ToLower(word), strings.ToLower(substring))
+	return strings.Contains(normalize.Letters(word), normalize.Letters(substring))
+}
+
+// lowerLetters returns the word's canonical letters, lower-cased, as runes
+func lowerLetters(word string) []rune {
+	return []rune(strings.ToLower(normalize.Letters(word)))
+}
+
+// Signature returns the word's letters, lower-cased and sorted, so that
+// anagrams share a signature ("LISTEN" and "silent" are both "eilnst")
+func (a *Analyzer) Signature(word string) string {
+	var letters []rune
+	for _, r := range lowerLetters(word) {
+		if unicode.IsLetter(r) {
+			letters = append(letters, r)
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return string(letters)
+}
+
+// AreAnagrams checks if two different words use exactly the same letters
+func (a *Analyzer) AreAnagrams(first, second string) bool {
+	if normalize.Equal(first, second) {
+		return false
+	}
+	sig := a.Signature(first)
+	return sig != "" && sig == a.Signature(second)
+}
+
+// AnagramsOf returns the dictionary words that are anagrams of word,
+// not counting the word itself
+func (a *Analyzer) AnagramsOf(word string) []string {
+	a.anagramOnce.Do(a.buildAnagramIndex)
+
+	var anagrams []string
+	for _, candidate := range a.anagramIndex[a.Signature(word)] {
+		if !normalize.Equal(candidate, word) {
+			anagrams = append(anagrams, candidate)
+		}
+	}
+	return anagrams
+}
+
+// buildAnagramIndex groups the dictionary words by signature
+func (a *Analyzer) buildAnagramIndex() {
+	a.anagramIndex = make(map[string][]string)
+	if a.lexicon == nil {
+		return
+	}
+	for _, word := range a.lexicon.AllWords() {
+		sig := a.Signature(word)
+		a.anagramIndex[sig] = append(a.anagramIndex[sig], word)
+	}
+}
+
+// minHiddenWordLength is the shortest dictionary word HiddenWords reports
+const minHiddenWordLength = 3
+
+// HiddenWords returns the dictionary words hidden inside an entry, ignoring
+// spaces and punctuation so words spanning a multi-word entry are found
+// ("TOP IGLOO" hides PIG). The entry itself is not counted.
+func (a *Analyzer) HiddenWords(entry string) []string {
+	if a.lexicon == nil {
+		return nil
+	}
+
+	letters := normalize.Letters(entry)
+
+	var hidden []string
+	for _, word := range a.lexicon.AllWords() {
+		if normalize.Length(word) < minHiddenWordLength || normalize.Length(word) >= len([]rune(letters)) {
+			continue
+		}
+		if a.ContainsSubstring(letters, word) {
+			hidden = append(hidden, word)
+		}
+	}
+	return hidden
 }
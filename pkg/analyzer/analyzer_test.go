@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -35,6 +36,13 @@ func TestHasCommonPrefix(t *testing.T) {
 			expected: "",
 			hasIt:    false,
 		},
+		{
+			name:     "accents and phrases",
+			words:    []string{"ÉCLAIR", "eclipse", "E-CL OWN"},
+			length:   3,
+			expected: "ecl",
+			hasIt:    true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -73,6 +81,16 @@ func TestAllSameLength(t *testing.T) {
 			words:    []string{},
 			expected: true,
 		},
+		{
+			name:     "counts letters, not bytes or spaces",
+			words:    []string{"CAFÉ", "BALD EAGLE", "PIÑATA"},
+			expected: false,
+		},
+		{
+			name:     "accented letters count once",
+			words:    []string{"CAFÉ", "NAÏF", "X-RAY", "DON'T"},
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -84,3 +102,198 @@ func TestAllSameLength(t *testing.T) {
 		})
 	}
 }
+
+func TestAnagrams(t *testing.T) {
+	a := New()
+
+	if got := a.Signature("Listen!"); got != "eilnst" {
+		t.Errorf("expected signature %q, got %q", "eilnst", got)
+	}
+
+	tests := []struct {
+		first, second string
+		expected      bool
+	}{
+		{"LISTEN", "SILENT", true},
+		{"MELON", "lemon", true},
+		{"MELON", "MELON", false},
+		{"CAT", "DOG", false},
+	}
+	for _, tt := range tests {
+		if got := a.AreAnagrams(tt.first, tt.second); got != tt.expected {
+			t.Errorf("AreAnagrams(%q, %q) = %v, want %v", tt.first, tt.second, got, tt.expected)
+		}
+	}
+
+	anagrams := a.AnagramsOf("LUMP")
+	if len(anagrams) != 1 || anagrams[0] != "PLUM" {
+		t.Errorf("expected LUMP to be an anagram of PLUM, got %v", anagrams)
+	}
+	if got := a.AnagramsOf("PLUM"); len(got) != 0 {
+		t.Errorf("expected no other anagrams of PLUM, got %v", got)
+	}
+}
+
+func TestHiddenWords(t *testing.T) {
+	a := New()
+
+	tests := []struct {
+		entry    string
+		expected string
+	}{
+		{"SCOWL", "OWL"},
+		{"TOP IGLOO", "PIG"},
+		{"Cat-alog", "CAT"},
+	}
+	for _, tt := range tests {
+		found := false
+		for _, word := range a.HiddenWords(tt.entry) {
+			if word == tt.expected {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %q to hide %q, got %v", tt.entry, tt.expected, a.HiddenWords(tt.entry))
+		}
+	}
+
+	for _, word := range a.HiddenWords("OWL") {
+		if word == "OWL" {
+			t.Error("an entry should not hide itself")
+		}
+	}
+}
+
+func TestSoundex(t *testing.T) {
+	a := New()
+
+	tests := map[string]string{
+		"ROBERT":   "R163",
+		"Rupert":   "R163",
+		"ASHCRAFT": "A261",
+		"TYMCZAK":  "T522",
+		"LEE":      "L000",
+	}
+	for word, want := range tests {
+		if got := a.Soundex(word); got != want {
+			t.Errorf("Soundex(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestMetaphone(t *testing.T) {
+	a := New()
+
+	pairs := [][2]string{
+		{"KNIGHT", "NIGHT"},
+		{"PHONE", "FONE"},
+		{"WRITE", "RITE"},
+		{"THUMB", "THUM"},
+	}
+	for _, pair := range pairs {
+		if a.Metaphone(pair[0]) != a.Metaphone(pair[1]) {
+			t.Errorf("expected %q (%s) and %q (%s) to share a key",
+				pair[0], a.Metaphone(pair[0]), pair[1], a.Metaphone(pair[1]))
+		}
+	}
+	if a.Metaphone("CAT") == a.Metaphone("DOG") {
+		t.Error("expected CAT and DOG to differ")
+	}
+}
+
+func TestHomophones(t *testing.T) {
+	a := New()
+
+	homophones := a.Homophones("SEA")
+	found := false
+	for _, word := range homophones {
+		if word == "C" {
+			found = true
+		}
+		if word == "SEA" {
+			t.Error("a word is not its own homophone")
+		}
+	}
+	if !found {
+		t.Errorf("expected SEA to sound like C, got %v", homophones)
+	}
+
+	if !a.SoundsLike("won", "ONE") {
+		t.Error("expected WON to sound like ONE")
+	}
+	if a.SoundsLike("ONE", "TWO") {
+		t.Error("expected ONE not to sound like TWO")
+	}
+}
+
+func TestRhymesAndSyllables(t *testing.T) {
+	a := New()
+
+	if key, ok := a.RhymeKey("CAT"); !ok || key != "AE T" {
+		t.Errorf("expected CAT to have rhyme key AE T, got %q", key)
+	}
+	if !a.Rhymes("CAT", "hat") {
+		t.Error("expected CAT to rhyme with HAT")
+	}
+	if a.Rhymes("CAT", "CAT") || a.Rhymes("CAT", "BEE") {
+		t.Error("expected CAT to rhyme with neither itself nor BEE")
+	}
+
+	tests := []struct {
+		word      string
+		syllables int
+	}{
+		{"CAT", 1},
+		{"APPLE", 2},
+		{"BANANA", 3},
+		{"STONE", 1}, // Not in the table: spelling fallback
+		{"PICKLE", 2},
+		{"ELEPHANTINE", 4},
+	}
+	for _, tt := range tests {
+		if got := a.Syllables(tt.word); got != tt.syllables {
+			t.Errorf("Syllables(%q) = %d, expected %d", tt.word, got, tt.syllables)
+		}
+	}
+
+	if pattern, ok := a.StressPattern("BANANA"); !ok || pattern != "010" {
+		t.Errorf("expected BANANA to have stress pattern 010, got %q", pattern)
+	}
+	if !a.AllSameSyllables([]string{"CAT", "DOG", "TREE"}) {
+		t.Error("expected one-syllable words to match")
+	}
+}
+
+func TestEdits(t *testing.T) {
+	a := New()
+
+	tests := []struct {
+		word string
+		edit Edit
+	}{
+		{"BEAR", Edit{Kind: DropFirst, Result: "EAR"}},
+		{"PLUMS", Edit{Kind: DropLast, Result: "PLUM"}},
+		{"lim", Edit{Kind: AddLetter, Result: "LIME"}},
+		{"COG", Edit{Kind: ChangeLetter, Result: "DOG"}},
+		{"TAR", Edit{Kind: Reverse, Result: "RAT"}},
+	}
+
+	for _, tt := range tests {
+		found := false
+		for _, edit := range a.DictionaryEdits(tt.word) {
+			if edit == tt.edit {
+				found = true
+			}
+			if edit.Result == strings.ToUpper(tt.word) {
+				t.Errorf("%q should not be an edit of itself", edit.Result)
+			}
+		}
+		if !found {
+			t.Errorf("expected %v among the edits of %q", tt.edit, tt.word)
+		}
+	}
+
+	if edits := a.Edits(""); edits != nil {
+		t.Errorf("expected no edits of an empty word, got %v", edits)
+	}
+}
@@ -0,0 +1,94 @@
+package analyzer
+
+// EditKind names a one-letter transformation of a word
+type EditKind string
+
+const (
+	// DropFirst removes the first letter ("BEAR" to "EAR")
+	DropFirst EditKind = "drop first"
+	// DropLast removes the last letter ("PEAR" to "PEA")
+	DropLast EditKind = "drop last"
+	// AddLetter inserts one letter anywhere ("PAN" to "SPAN")
+	AddLetter EditKind = "add letter"
+	// ChangeLetter replaces one letter ("CAT" to "BAT")
+	ChangeLetter EditKind = "change letter"
+	// Reverse spells the word backwards ("STAR" to "RATS")
+	Reverse EditKind = "reverse"
+)
+
+// EditKinds lists every transformation Edits applies
+var EditKinds = []EditKind{DropFirst, DropLast, AddLetter, ChangeLetter, Reverse}
+
+// minEditWordLength is the shortest dictionary word DictionaryEdits reports
+const minEditWordLength = 3
+
+// Edit is the result of applying one transformation to a word
+type Edit struct {
+	Kind   EditKind
+	Result string
+}
+
+// Edits returns every distinct one-edit transformation of a word, upper-cased
+// and ignoring spaces and punctuation. The word itself is never included.
+func (a *Analyzer) Edits(word string) []Edit {
+	letters := upperLetters(word)
+	if len(letters) == 0 {
+		return nil
+	}
+
+	var edits []Edit
+	seen := map[Edit]bool{{Result: string(letters)}: true}
+	add := func(kind EditKind, result []rune) {
+		edit := Edit{Kind: kind, Result: string(result)}
+		if edit.Result == "" || edit.Result == string(letters) || seen[edit] {
+			return
+		}
+		seen[edit] = true
+		edits = append(edits, edit)
+	}
+
+	add(DropFirst, letters[1:])
+	add(DropLast, letters[:len(letters)-1])
+
+	for i := 0; i <= len(letters); i++ {
+		for r := 'A'; r <= 'Z'; r++ {
+			result := make([]rune, 0, len(letters)+1)
+			result = append(result, letters[:i]...)
+			result = append(result, r)
+			result = append(result, letters[i:]...)
+			add(AddLetter, result)
+		}
+	}
+
+	for i := range letters {
+		for r := 'A'; r <= 'Z'; r++ {
+			result := append([]rune(nil), letters...)
+			result[i] = r
+			add(ChangeLetter, result)
+		}
+	}
+
+	reversed := make([]rune, len(letters))
+	for i, r := range letters {
+		reversed[len(letters)-1-i] = r
+	}
+	add(Reverse, reversed)
+
+	return edits
+}
+
+// DictionaryEdits returns the one-edit transformations of a word that land
+// on a lexicon word, e.g. dropping the first letter of "BEAR" gives "EAR"
+func (a *Analyzer) DictionaryEdits(word string) []Edit {
+	if a.lexicon == nil {
+		return nil
+	}
+
+	var edits []Edit
+	for _, edit := range a.Edits(word) {
+		if len([]rune(edit.Result)) >= minEditWordLength && a.lexicon.Contains(edit.Result) {
+			edits = append(edits, edit)
+		}
+	}
+	return edits
+}
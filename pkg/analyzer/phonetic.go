@@ -0,0 +1,353 @@
+package analyzer
+
+import (
+	"connections/pkg/lexicon"
+	"connections/pkg/normalize"
+	"strings"
+	"unicode"
+)
+
+// soundexCodes maps letters to their Soundex digit; vowels and H, W, Y are 0
+var soundexCodes = map[rune]byte{
+	'B': '1', 'F': '1', 'P': '1', 'V': '1',
+	'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
+	'D': '3', 'T': '3',
+	'L': '4',
+	'M': '5', 'N': '5',
+	'R': '6',
+}
+
+// Soundex returns the four-character American Soundex code ("ROBERT" is R163)
+func (a *Analyzer) Soundex(word string) string {
+	letters := upperLetters(word)
+	if len(letters) == 0 {
+		return ""
+	}
+
+	code := []byte{byte(letters[0])}
+	last := soundexCodes[letters[0]]
+	for _, r := range letters[1:] {
+		digit, ok := soundexCodes[r]
+		switch {
+		case ok && digit != last:
+			code = append(code, digit)
+			last = digit
+		case !ok && r != 'H' && r != 'W':
+			// Vowels separate repeated codes; H and W do not
+			last = 0
+		}
+		if len(code) == 4 {
+			break
+		}
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code[:4])
+}
+
+// Metaphone returns a phonetic key using the original Metaphone rules, so
+// spellings that sound alike ("KNIGHT" and "NIGHT") usually share a key
+func (a *Analyzer) Metaphone(word string) string {
+	w := upperLetters(word)
+	if len(w) == 0 {
+		return ""
+	}
+
+	// Initial letter exceptions
+	switch {
+	case hasPrefix(w, "AE"), hasPrefix(w, "GN"), hasPrefix(w, "KN"), hasPrefix(w, "PN"), hasPrefix(w, "WR"):
+		w = w[1:]
+	case w[0] == 'X':
+		w[0] = 'S'
+	case hasPrefix(w, "WH"):
+		w = append([]rune{'W'}, w[2:]...)
+	}
+
+	at := func(i int) rune {
+		if i < 0 || i >= len(w) {
+			return 0
+		}
+		return w[i]
+	}
+	isVowel := func(r rune) bool { return strings.ContainsRune("AEIOU", r) }
+	frontVowel := func(r rune) bool { return r == 'E' || r == 'I' || r == 'Y' }
+
+	var key strings.Builder
+	for i, r := range w {
+		// Drop duplicate adjacent letters except C
+		if r != 'C' && i > 0 && at(i-1) == r {
+			continue
+		}
+
+		switch r {
+		case 'A', 'E', 'I', 'O', 'U':
+			if i == 0 {
+				key.WriteRune(r)
+			}
+		case 'B':
+			// Silent in final MB
+			if !(i == len(w)-1 && at(i-1) == 'M') {
+				key.WriteRune('B')
+			}
+		case 'C':
+			switch {
+			case at(i+1) == 'I' && at(i+2) == 'A':
+				key.WriteRune('X')
+			case at(i+1) == 'H':
+				if at(i-1) == 'S' {
+					key.WriteRune('K')
+				} else {
+					key.WriteRune('X')
+				}
+			case frontVowel(at(i + 1)):
+				if at(i-1) != 'S' {
+					key.WriteRune('S')
+				}
+			default:
+				key.WriteRune('K')
+			}
+		case 'D':
+			if at(i+1) == 'G' && frontVowel(at(i+2)) {
+				key.WriteRune('J')
+			} else {
+				key.WriteRune('T')
+			}
+		case 'G':
+			switch {
+			case at(i+1) == 'H' && !isVowel(at(i+2)) && at(i+2) != 0:
+				// Silent in GHT and similar
+			case at(i+1) == 'H' && at(i+2) == 0:
+				// Silent in final GH
+			case at(i+1) == 'N' && (at(i+2) == 0 || (at(i+2) == 'E' && at(i+3) == 'D' && at(i+4) == 0)):
+				// Silent in GN and GNED
+			case frontVowel(at(i+1)) && at(i-1) != 'G':
+				key.WriteRune('J')
+			default:
+				key.WriteRune('K')
+			}
+		case 'H':
+			if isVowel(at(i+1)) && !strings.ContainsRune("CSPTG", at(i-1)) {
+				key.WriteRune('H')
+			}
+		case 'K':
+			if at(i-1) != 'C' {
+				key.WriteRune('K')
+			}
+		case 'P':
+			if at(i+1) == 'H' {
+				key.WriteRune('F')
+			} else {
+				key.WriteRune('P')
+			}
+		case 'Q':
+			key.WriteRune('K')
+		case 'S':
+			if at(i+1) == 'H' || (at(i+1) == 'I' && (at(i+2) == 'O' || at(i+2) == 'A')) {
+				key.WriteRune('X')
+			} else {
+				key.WriteRune('S')
+			}
+		case 'T':
+			switch {
+			case at(i+1) == 'I' && (at(i+2) == 'O' || at(i+2) == 'A'):
+				key.WriteRune('X')
+			case at(i+1) == 'H':
+				key.WriteRune('0') // "TH"
+			case at(i+1) == 'C' && at(i+2) == 'H':
+				// Silent in TCH
+			default:
+				key.WriteRune('T')
+			}
+		case 'V':
+			key.WriteRune('F')
+		case 'W', 'Y':
+			if isVowel(at(i + 1)) {
+				key.WriteRune(r)
+			}
+		case 'X':
+			key.WriteString("KS")
+		case 'Z':
+			key.WriteRune('S')
+		default:
+			// F, J, L, M, N, R are kept as is
+			key.WriteRune(r)
+		}
+	}
+
+	return key.String()
+}
+
+// Pronunciation returns a word's ARPAbet phonemes from the pronunciation table
+func (a *Analyzer) Pronunciation(word string) ([]string, bool) {
+	if a.lexicon == nil {
+		return nil, false
+	}
+	return a.lexicon.Pronunciation(word)
+}
+
+// SoundKey identifies how a word sounds: its stress-free pronunciation when
+// the table has one, otherwise its Metaphone key
+func (a *Analyzer) SoundKey(word string) string {
+	if phonemes, ok := a.Pronunciation(word); ok {
+		return lexicon.SoundKey(phonemes)
+	}
+	return "~" + a.Metaphone(word)
+}
+
+// SoundsLike checks if two differently spelled words sound the same
+func (a *Analyzer) SoundsLike(first, second string) bool {
+	if normalize.Equal(first, second) {
+		return false
+	}
+	_, firstKnown := a.Pronunciation(first)
+	_, secondKnown := a.Pronunciation(second)
+	if firstKnown && secondKnown {
+		return a.SoundKey(first) == a.SoundKey(second)
+	}
+	return a.Metaphone(first) != "" && a.Metaphone(first) == a.Metaphone(second)
+}
+
+// Homophones returns the words in the pronunciation table that sound like
+// word but are spelled differently
+func (a *Analyzer) Homophones(word string) []string {
+	phonemes, ok := a.Pronunciation(word)
+	if !ok {
+		return nil
+	}
+
+	var homophones []string
+	for _, other := range a.lexicon.WordsSounding(lexicon.SoundKey(phonemes)) {
+		if !normalize.Equal(other, word) {
+			homophones = append(homophones, other)
+		}
+	}
+	return homophones
+}
+
+// upperLetters returns the word's letters, upper-cased with accents folded
+func upperLetters(word string) []rune {
+	var letters []rune
+	for _, r := range normalize.Letters(word) {
+		if unicode.IsLetter(r) {
+			letters = append(letters, r)
+		}
+	}
+	return letters
+}
+
+// hasPrefix reports whether the runes start with prefix
+func hasPrefix(runes []rune, prefix string) bool {
+	return strings.HasPrefix(string(runes), prefix)
+}
+
+// isVowelPhoneme reports whether an ARPAbet phoneme is a vowel (vowels carry
+// a stress digit)
+func isVowelPhoneme(phoneme string) bool {
+	return phoneme != "" && strings.ContainsAny(phoneme[len(phoneme)-1:], "012")
+}
+
+// RhymeKey returns the sound of a word from its last stressed vowel to the
+// end, so rhyming words share a key ("CAT" and "HAT" are both "AE T")
+func (a *Analyzer) RhymeKey(word string) (string, bool) {
+	phonemes, ok := a.Pronunciation(word)
+	if !ok {
+		return "", false
+	}
+
+	start := -1
+	for i := len(phonemes) - 1; i >= 0; i-- {
+		if strings.HasSuffix(phonemes[i], "1") || strings.HasSuffix(phonemes[i], "2") {
+			start = i
+			break
+		}
+		if start == -1 && isVowelPhoneme(phonemes[i]) {
+			// Remember the last vowel in case nothing is stressed
+			start = i
+		}
+	}
+	if start == -1 {
+		return "", false
+	}
+
+	return lexicon.SoundKey(phonemes[start:]), true
+}
+
+// Rhymes checks if two different words rhyme
+func (a *Analyzer) Rhymes(first, second string) bool {
+	if normalize.Equal(first, second) {
+		return false
+	}
+	firstKey, ok := a.RhymeKey(first)
+	if !ok {
+		return false
+	}
+	secondKey, ok := a.RhymeKey(second)
+	return ok && firstKey == secondKey
+}
+
+// Syllables returns the number of syllables in a word, from the
+// pronunciation table when possible and otherwise by counting vowel groups
+func (a *Analyzer) Syllables(word string) int {
+	if phonemes, ok := a.Pronunciation(word); ok {
+		count := 0
+		for _, phoneme := range phonemes {
+			if isVowelPhoneme(phoneme) {
+				count++
+			}
+		}
+		return count
+	}
+
+	letters := upperLetters(word)
+	count := 0
+	inVowel := false
+	for i, r := range letters {
+		vowel := strings.ContainsRune("AEIOUY", r)
+		if vowel && !inVowel {
+			count++
+		}
+		inVowel = vowel
+		// A final silent E does not add a syllable ("STONE"), but "-LE" does ("APPLE")
+		if i == len(letters)-1 && r == 'E' && count > 1 && !strings.HasSuffix(string(letters), "LE") && !strings.ContainsRune("AEIOUY", letters[i-1]) {
+			count--
+		}
+	}
+	if count == 0 && len(letters) > 0 {
+		count = 1
+	}
+	return count
+}
+
+// StressPattern returns the stress digits of a word's vowels, e.g. "10" for
+// "APPLE" and "010" for "BANANA"
+func (a *Analyzer) StressPattern(word string) (string, bool) {
+	phonemes, ok := a.Pronunciation(word)
+	if !ok {
+		return "", false
+	}
+
+	var pattern strings.Builder
+	for _, phoneme := range phonemes {
+		if isVowelPhoneme(phoneme) {
+			pattern.WriteByte(phoneme[len(phoneme)-1])
+		}
+	}
+	return pattern.String(), true
+}
+
+// AllSameSyllables checks if all words have the same number of syllables
+func (a *Analyzer) AllSameSyllables(words []string) bool {
+	if len(words) == 0 {
+		return true
+	}
+
+	count := a.Syllables(words[0])
+	for _, word := range words[1:] {
+		if a.Syllables(word) != count {
+			return false
+		}
+	}
+
+	return true
+}
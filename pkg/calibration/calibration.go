@@ -0,0 +1,189 @@
+package calibration
+
+import (
+	"connections/pkg/normalize"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// binCount is the number of equal-width raw-confidence bins per key
+const binCount = 10
+
+// priorWeight is how many pseudo-observations at the raw confidence each bin
+// starts with, so sparse bins stay close to the raw value
+const priorWeight = 2.0
+
+// Puzzle is a solved puzzle from the archive
+type Puzzle struct {
+	Date   string     `json:"date,omitempty"`
+	Words  []string   `json:"words"`
+	Groups [][]string `json:"groups"` // The four correct groups
+}
+
+// Observation is one predicted group and whether it was a correct group
+type Observation struct {
+	Source     string  `json:"source"`
+	Strategy   string  `json:"strategy,omitempty"`
+	Confidence float64 `json:"confidence"`
+	Correct    bool    `json:"correct"`
+}
+
+// Bin counts outcomes for one raw-confidence range
+type Bin struct {
+	Count   float64 `json:"count"`
+	Correct float64 `json:"correct"`
+}
+
+// Calibrator maps raw confidences to observed accuracy. It keeps a histogram
+// per source and strategy, falling back to per-source and then overall
+// histograms when a key has no history.
+type Calibrator struct {
+	Bins map[string][]Bin `json:"bins"`
+}
+
+// LoadArchive reads a JSON array of solved puzzles
+func LoadArchive(path string) ([]Puzzle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	var puzzles []Puzzle
+	if err := json.Unmarshal(data, &puzzles); err != nil {
+		return nil, fmt.Errorf("failed to parse archive: %w", err)
+	}
+
+	for i, puzzle := range puzzles {
+		if len(puzzle.Words) != 16 || len(puzzle.Groups) != 4 {
+			return nil, fmt.Errorf("archive puzzle %d: expected 16 words in 4 groups", i+1)
+		}
+	}
+
+	return puzzles, nil
+}
+
+// Label reports whether words form one of the puzzle's correct groups
+func (p Puzzle) Label(words []string) bool {
+	for _, group := range p.Groups {
+		if sameWords(group, words) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fit learns a calibrator from observations
+func Fit(observations []Observation) *Calibrator {
+	c := &Calibrator{Bins: make(map[string][]Bin)}
+	for _, o := range observations {
+		for _, key := range keys(o.Source, o.Strategy) {
+			bins, ok := c.Bins[key]
+			if !ok {
+				bins = make([]Bin, binCount)
+				c.Bins[key] = bins
+			}
+			b := &bins[binIndex(o.Confidence)]
+			b.Count++
+			if o.Correct {
+				b.Correct++
+			}
+		}
+	}
+	return c
+}
+
+// Calibrate returns the accuracy historically achieved by groups with this
+// source, strategy and raw confidence. Without history the raw value is kept.
+func (c *Calibrator) Calibrate(source, strategy string, raw float64) float64 {
+	if c == nil {
+		return raw
+	}
+	raw = math.Max(0, math.Min(1, raw))
+
+	for _, key := range keys(source, strategy) {
+		bins, ok := c.Bins[key]
+		if !ok {
+			continue
+		}
+		b := bins[binIndex(raw)]
+		if b.Count == 0 {
+			continue
+		}
+		return (b.Correct + priorWeight*raw) / (b.Count + priorWeight)
+	}
+
+	return raw
+}
+
+// Load reads a calibrator saved with Save
+func Load(path string) (*Calibrator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calibration: %w", err)
+	}
+
+	var c Calibrator
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse calibration: %w", err)
+	}
+	for key, bins := range c.Bins {
+		if len(bins) != binCount {
+			return nil, fmt.Errorf("calibration key %q has %d bins, expected %d", key, len(bins), binCount)
+		}
+	}
+
+	return &c, nil
+}
+
+// Save writes the calibrator as JSON
+func (c *Calibrator) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal calibration: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write calibration: %w", err)
+	}
+	return nil
+}
+
+// keys lists histogram keys from most to least specific
+func keys(source, strategy string) []string {
+	return []string{source + "/" + strategy, source, "*"}
+}
+
+// binIndex maps a confidence to its bin
+func binIndex(confidence float64) int {
+	i := int(confidence * binCount)
+	if i < 0 {
+		return 0
+	}
+	if i >= binCount {
+		return binCount - 1
+	}
+	return i
+}
+
+// sameWords reports whether two groups hold the same words, ignoring order and case
+func sameWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, word := range a {
+		found := false
+		for i, other := range b {
+			if !used[i] && normalize.Equal(word, other) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
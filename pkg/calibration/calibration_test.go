@@ -0,0 +1,78 @@
+package calibration
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestCalibrate(t *testing.T) {
+	var observations []Observation
+	// Pattern prefix groups at 0.5 are right 1 time in 10
+	for i := 0; i < 10; i++ {
+		observations = append(observations, Observation{
+			Source: "pattern", Strategy: "prefix", Confidence: 0.5, Correct: i == 0,
+		})
+	}
+	// AI groups at 0.95 are right 8 times in 8
+	for i := 0; i < 8; i++ {
+		observations = append(observations, Observation{Source: "ai", Confidence: 0.95, Correct: true})
+	}
+
+	c := Fit(observations)
+
+	tests := []struct {
+		name     string
+		source   string
+		strategy string
+		raw      float64
+		want     float64
+	}{
+		{"overconfident strategy", "pattern", "prefix", 0.5, (1 + 2*0.5) / 12},
+		{"falls back to source", "pattern", "suffix", 0.55, (1 + 2*0.55) / 12},
+		{"underconfident AI", "ai", "", 0.95, (8 + 2*0.95) / 10},
+		{"no history keeps raw", "ai", "", 0.2, 0.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.Calibrate(tt.source, tt.strategy, tt.raw)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Calibrate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilCalibratorKeepsRaw(t *testing.T) {
+	var c *Calibrator
+	if got := c.Calibrate("ai", "", 0.7); got != 0.7 {
+		t.Errorf("expected raw confidence, got %v", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	c := Fit([]Observation{{Source: "ai", Confidence: 0.9, Correct: true}})
+	path := filepath.Join(t.TempDir(), "calibration.json")
+
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := loaded.Calibrate("ai", "", 0.9), c.Calibrate("ai", "", 0.9); got != want {
+		t.Errorf("loaded calibrator gives %v, want %v", got, want)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	p := Puzzle{Groups: [][]string{{"BASS", "TROUT", "PERCH", "SOLE"}}}
+	if !p.Label([]string{"sole", "PERCH", "Trout", "BASS"}) {
+		t.Error("expected reordered group to be labelled correct")
+	}
+	if p.Label([]string{"BASS", "TROUT", "PERCH", "CARP"}) {
+		t.Error("expected wrong group to be labelled incorrect")
+	}
+}
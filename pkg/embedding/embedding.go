@@ -0,0 +1,315 @@
+package embedding
+
+import (
+	"bufio"
+	"bytes"
+	"connections/pkg/normalize"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// magic starts every quantized model file
+var magic = []byte("CEMB")
+
+// formatVersion is the quantized file layout version
+const formatVersion = 1
+
+// Model holds word vectors quantized to one signed byte per dimension,
+// about a quarter of the size of the float vectors they came from
+type Model struct {
+	dim     int
+	index   map[string]int // folded lower-case word -> row
+	words   []string
+	vectors []int8    // rows of dim values
+	scales  []float32 // per-row scale back to the original values
+}
+
+// New creates an empty model for vectors of the given dimension
+func New(dim int) *Model {
+	return &Model{dim: dim, index: make(map[string]int)}
+}
+
+// Dim returns the vector dimension
+func (m *Model) Dim() int {
+	return m.dim
+}
+
+// Len returns the number of words in the model
+func (m *Model) Len() int {
+	return len(m.words)
+}
+
+// Add quantizes and stores a word's vector under its folded, lower-case
+// letters ("x-ray" is stored as "xray"). A word added twice keeps its first
+// vector, as GloVe files list the most frequent sense first; tokens with no
+// letters or digits are skipped.
+func (m *Model) Add(word string, vector []float32) error {
+	if len(vector) != m.dim {
+		return fmt.Errorf("vector for %q has %d dimensions, expected %d", word, len(vector), m.dim)
+	}
+	key := strings.ToLower(normalize.Letters(word))
+	if key == "" {
+		return nil
+	}
+	if _, ok := m.index[key]; ok {
+		return nil
+	}
+
+	var peak float64
+	for _, v := range vector {
+		peak = math.Max(peak, math.Abs(float64(v)))
+	}
+	scale := float32(peak / 127)
+
+	m.index[key] = len(m.words)
+	m.words = append(m.words, key)
+	m.scales = append(m.scales, scale)
+	for _, v := range vector {
+		var q int8
+		if scale > 0 {
+			q = int8(math.Round(float64(v / scale)))
+		}
+		m.vectors = append(m.vectors, q)
+	}
+	return nil
+}
+
+// Vector returns the vector for a word or phrase. Phrases ("GO FISH") and
+// hyphenated entries average the vectors of their parts; ok is false if any
+// part is unknown. Accents are folded, so "Café" finds "cafe".
+func (m *Model) Vector(word string) ([]float32, bool) {
+	parts := normalize.Tokens(word)
+	for i, part := range parts {
+		parts[i] = strings.ToLower(part)
+	}
+	if len(parts) == 0 {
+		return nil, false
+	}
+	if row, ok := m.index[strings.Join(parts, "")]; ok && len(parts) > 1 {
+		// Prefer a closed compound ("football") when the model has one
+		parts = []string{m.words[row]}
+	}
+
+	vector := make([]float32, m.dim)
+	for _, part := range parts {
+		row, ok := m.index[part]
+		if !ok {
+			return nil, false
+		}
+		scale := m.scales[row]
+		for i, q := range m.vectors[row*m.dim : (row+1)*m.dim] {
+			vector[i] += float32(q) * scale / float32(len(parts))
+		}
+	}
+	return vector, true
+}
+
+// Similarity returns the cosine similarity of two words' vectors
+func (m *Model) Similarity(first, second string) (float64, bool) {
+	a, ok := m.Vector(first)
+	if !ok {
+		return 0, false
+	}
+	b, ok := m.Vector(second)
+	if !ok {
+		return 0, false
+	}
+	return Cosine(a, b), true
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 if either is zero
+func Cosine(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// Load reads a model from disk, either a quantized file written by Save or
+// a GloVe-style text file with one "word v1 v2 ..." line per word
+func Load(path string) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embeddings: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embeddings: %w", err)
+	}
+
+	r := bufio.NewReader(f)
+	head, err := r.Peek(len(magic))
+	if err == nil && bytes.Equal(head, magic) {
+		m, err := readQuantized(r, info.Size())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return m, nil
+	}
+
+	m, err := ReadText(r, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// ReadText parses GloVe-style text vectors, keeping at most limit words
+// (0 keeps all). GloVe files are sorted by frequency, so a limit keeps the
+// most common words. A word2vec "count dim" header line is skipped.
+func ReadText(r io.Reader, limit int) (*Model, error) {
+	var m *Model
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if line == 1 && len(fields) == 2 {
+			continue
+		}
+
+		if m == nil {
+			m = New(len(fields) - 1)
+		}
+		vector := make([]float32, len(fields)-1)
+		for i, field := range fields[1:] {
+			v, err := strconv.ParseFloat(field, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %q", line, field)
+			}
+			vector[i] = float32(v)
+		}
+		if err := m.Add(fields[0], vector); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if limit > 0 && m.Len() >= limit {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if m == nil || m.dim == 0 {
+		return nil, fmt.Errorf("no vectors found")
+	}
+	return m, nil
+}
+
+// Save writes the model in the quantized format
+func (m *Model) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create embeddings: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	if err := m.write(w); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write embeddings: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write embeddings: %w", err)
+	}
+	return f.Close()
+}
+
+// write encodes the model as: magic, version, dim and count, then for each
+// word its length-prefixed bytes, its scale and its dim quantized values.
+// Integers are little-endian.
+func (m *Model) write(w io.Writer) error {
+	header := []any{magic, uint8(formatVersion), uint32(m.dim), uint32(len(m.words))}
+	for _, v := range header {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+
+	for row, word := range m.words {
+		if len(word) > math.MaxUint8 {
+			return fmt.Errorf("word %q is too long", word)
+		}
+		record := []any{uint8(len(word)), []byte(word), m.scales[row], m.vectors[row*m.dim : (row+1)*m.dim]}
+		for _, v := range record {
+			if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readQuantized decodes a model written by write. size is the length of the
+// encoded model, which bounds the word count a header may claim.
+func readQuantized(r io.Reader, size int64) (*Model, error) {
+	var header struct {
+		Magic   [4]byte
+		Version uint8
+		Dim     uint32
+		Count   uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported format version %d", header.Version)
+	}
+	if header.Dim == 0 {
+		return nil, fmt.Errorf("invalid dimension 0")
+	}
+	// Each record holds at least a length byte, a scale and dim values.
+	// Dividing rather than multiplying keeps a crafted header from
+	// overflowing the check.
+	remaining := size - int64(binary.Size(header))
+	if remaining < 0 || uint64(header.Count) > uint64(remaining)/(5+uint64(header.Dim)) {
+		return nil, fmt.Errorf("header claims %d words of %d dimensions, more than the file holds", header.Count, header.Dim)
+	}
+
+	m := New(int(header.Dim))
+	m.words = make([]string, 0, header.Count)
+	m.scales = make([]float32, 0, header.Count)
+	m.vectors = make([]int8, 0, int(header.Count)*m.dim)
+
+	row := make([]int8, m.dim)
+	for i := uint32(0); i < header.Count; i++ {
+		var length uint8
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return nil, err
+		}
+		word := make([]byte, length)
+		if _, err := io.ReadFull(r, word); err != nil {
+			return nil, err
+		}
+		var scale float32
+		if err := binary.Read(r, binary.LittleEndian, &scale); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, row); err != nil {
+			return nil, err
+		}
+
+		m.index[string(word)] = len(m.words)
+		m.words = append(m.words, string(word))
+		m.scales = append(m.scales, scale)
+		m.vectors = append(m.vectors, row...)
+	}
+	return m, nil
+}
@@ -0,0 +1,132 @@
+package embedding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const glove = `4 3
+salmon 0.9 0.1 0.0
+trout 0.8 0.2 0.1
+go 0.0 0.0 1.0
+fish 1.0 0.0 0.0
+salmon 0.0 1.0 0.0
+`
+
+func TestReadText(t *testing.T) {
+	m, err := ReadText(strings.NewReader(glove), 0)
+	if err != nil {
+		t.Fatalf("ReadText: %v", err)
+	}
+	if m.Dim() != 3 || m.Len() != 4 {
+		t.Fatalf("expected 4 words of 3 dimensions, got %d of %d", m.Len(), m.Dim())
+	}
+
+	similar, ok := m.Similarity("SALMON", "Trout")
+	if !ok || similar < 0.9 {
+		t.Errorf("expected SALMON and TROUT to be similar, got %.2f", similar)
+	}
+	different, _ := m.Similarity("salmon", "go")
+	if different > similar {
+		t.Errorf("expected SALMON to be closer to TROUT than to GO")
+	}
+
+	// Phrases average their parts
+	phrase, ok := m.Vector("GO FISH")
+	if !ok || math.Abs(float64(phrase[0]-phrase[2])) > 0.01 {
+		t.Errorf("expected GO FISH to average its parts, got %v", phrase)
+	}
+	if _, ok := m.Vector("GO SWIM"); ok {
+		t.Error("expected a phrase with an unknown part to be missing")
+	}
+
+	limited, err := ReadText(strings.NewReader(glove), 2)
+	if err != nil || limited.Len() != 2 {
+		t.Errorf("expected the limit to keep 2 words, got %v", err)
+	}
+
+	if _, err := ReadText(strings.NewReader("salmon 0.1 x\n"), 0); err == nil {
+		t.Error("expected an error for an invalid value")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	m, err := ReadText(strings.NewReader(glove), 0)
+	if err != nil {
+		t.Fatalf("ReadText: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "vectors.bin")
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if loaded.Dim() != m.Dim() || loaded.Len() != m.Len() {
+		t.Fatalf("expected %d words of %d dimensions, got %d of %d", m.Len(), m.Dim(), loaded.Len(), loaded.Dim())
+	}
+	want, _ := m.Similarity("salmon", "trout")
+	got, _ := loaded.Similarity("salmon", "trout")
+	if want != got {
+		t.Errorf("expected similarity %v after a round trip, got %v", want, got)
+	}
+}
+
+func TestReadQuantizedCorruptHeader(t *testing.T) {
+	var valid bytes.Buffer
+	m, _ := ReadText(strings.NewReader(glove), 0)
+	if err := m.write(&valid); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		dim   uint32
+		count uint32
+	}{
+		{"zero dimension", 0, 4},
+		{"count past the file", 3, 1 << 30},
+		{"dimension past the file", 1 << 30, 4},
+		{"size that overflows", 1<<32 - 5, 1 << 31},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Clone(valid.Bytes())
+			binary.LittleEndian.PutUint32(data[5:], tt.dim)
+			binary.LittleEndian.PutUint32(data[9:], tt.count)
+			if _, err := readQuantized(bytes.NewReader(data), int64(len(data))); err == nil {
+				t.Error("expected an error for a corrupt header")
+			}
+		})
+	}
+
+	if _, err := readQuantized(bytes.NewReader(valid.Bytes()), int64(valid.Len())); err != nil {
+		t.Errorf("readQuantized() error = %v for a valid model", err)
+	}
+}
+
+func TestVectorNormalization(t *testing.T) {
+	m := New(2)
+	for word, vector := range map[string][]float32{"café": {1, 0}, "x-ray": {0, 1}, ".": {1, 1}} {
+		if err := m.Add(word, vector); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if m.Len() != 2 {
+		t.Errorf("expected punctuation-only tokens to be skipped, got %d words", m.Len())
+	}
+	for _, word := range []string{"CAFE", "Café", "X-RAY", "XRAY", "x ray"} {
+		if _, ok := m.Vector(word); !ok {
+			t.Errorf("expected a vector for %q", word)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package grouper
+
+import (
+	"connections/pkg/analyzer"
+	"sort"
+)
+
+// StrategyAnagram is the name of the anagram strategy
+const StrategyAnagram = "anagram"
+
+func init() {
+	Register(&anagramStrategy{analyzer: analyzer.New()})
+}
+
+// anagramStrategy finds words that are anagrams of members of one lexicon
+// category ("anagrams of fruits": MELON, LUMP, REAP, MILE) and words that
+// are all anagrams of each other
+type anagramStrategy struct {
+	analyzer *analyzer.Analyzer
+}
+
+func (s *anagramStrategy) Name() string { return StrategyAnagram }
+
+func (s *anagramStrategy) FindGroups(words []string) []Candidate {
+	var candidates []Candidate
+
+	// Anagrams of words in a shared category
+	byCategory := make(map[string][]string)
+	for _, word := range words {
+		seen := make(map[string]bool)
+		for _, anagram := range s.analyzer.AnagramsOf(word) {
+			for _, category := range s.analyzer.Lexicon().CategoriesOf(anagram) {
+				if !seen[category] {
+					seen[category] = true
+					byCategory[category] = append(byCategory[category], word)
+				}
+			}
+		}
+	}
+
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		for _, quartet := range spreadQuartets(byCategory[category], maxBucketCandidates) {
+			candidates = append(candidates, Candidate{
+				Words:      quartet,
+				Theme:      "Anagrams of " + category,
+				Confidence: 0.7,
+			})
+		}
+	}
+
+	// Words that are anagrams of each other
+	bySignature := make(map[string][]string)
+	var signatures []string
+	for _, word := range words {
+		sig := s.analyzer.Signature(word)
+		if sig == "" {
+			continue
+		}
+		if _, ok := bySignature[sig]; !ok {
+			signatures = append(signatures, sig)
+		}
+		bySignature[sig] = append(bySignature[sig], word)
+	}
+
+	for _, sig := range signatures {
+		for _, quartet := range spreadQuartets(bySignature[sig], maxBucketCandidates) {
+			candidates = append(candidates, Candidate{
+				Words:      quartet,
+				Theme:      "Anagrams of each other",
+				Confidence: 0.6,
+			})
+		}
+	}
+
+	return candidates
+}
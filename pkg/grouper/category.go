@@ -0,0 +1,65 @@
+package grouper
+
+import (
+	"connections/pkg/lexicon"
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
+
+// StrategyCategory is the name of the category knowledge-base strategy
+const StrategyCategory = "category"
+
+func init() {
+	Register(&categoryStrategy{lexicon: lexicon.Default()})
+}
+
+// categoryStrategy proposes the words that belong to the same lexicon
+// category (fish, NFL teams, Greek letters, ...), giving the grouper some
+// semantic knowledge when no AI is available
+type categoryStrategy struct {
+	lexicon *lexicon.Lexicon
+}
+
+func (s *categoryStrategy) Name() string { return StrategyCategory }
+
+func (s *categoryStrategy) FindGroups(words []string) []Candidate {
+	var candidates []Candidate
+	for _, category := range s.lexicon.Categories() {
+		var members []string
+		for _, word := range words {
+			if s.lexicon.InCategory(category, word) {
+				members = append(members, word)
+			}
+		}
+
+		confidence := bucketConfidence(categoryConfidence(len(s.lexicon.Words(category))), len(members))
+		for _, quartet := range spreadQuartets(members, maxBucketCandidates) {
+			candidates = append(candidates, Candidate{
+				Words:      quartet,
+				Theme:      categoryTheme(category),
+				Confidence: confidence,
+			})
+		}
+	}
+	return candidates
+}
+
+// categoryConfidence scales with how specific a category is: four of the
+// eight planets is strong evidence, four of forty fish much weaker
+func categoryConfidence(size int) float64 {
+	if size < 4 {
+		size = 4
+	}
+	confidence := 0.9 - 0.08*math.Log2(float64(size)/4)
+	return math.Max(0.5, math.Min(0.85, confidence))
+}
+
+// categoryTheme capitalizes a category name for display ("fish" is "Fish")
+func categoryTheme(category string) string {
+	first, size := utf8.DecodeRuneInString(category)
+	if first == utf8.RuneError {
+		return category
+	}
+	return string(unicode.ToUpper(first)) + category[size:]
+}
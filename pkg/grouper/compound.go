@@ -0,0 +1,62 @@
+package grouper
+
+import (
+	"connections/pkg/lexicon"
+	"sort"
+)
+
+func init() {
+	Register(&compoundStrategy{lexicon: lexicon.Default()})
+}
+
+// compoundStrategy solves "fill in the blank" groups: it looks for a missing
+// word X that turns four entries into known compounds or phrases, either as
+// entry+X (___BALL: FOOT, BASKET, SNOW, EYE) or X+entry (FIRE___: ALARM,
+// WORK, PLACE, MAN)
+type compoundStrategy struct {
+	lexicon *lexicon.Lexicon
+}
+
+func (s *compoundStrategy) Name() string { return StrategyCompound }
+
+func (s *compoundStrategy) FindGroups(words []string) []Candidate {
+	// Themes are the blank pattern: "___BALL" or "FIRE___"
+	byBlank := make(map[string][]string)
+	for _, word := range words {
+		seen := make(map[string]bool)
+		for _, c := range s.lexicon.CompoundsStartingWith(word) {
+			theme := "___" + c.Right
+			if !seen[theme] {
+				seen[theme] = true
+				byBlank[theme] = append(byBlank[theme], word)
+			}
+		}
+		for _, c := range s.lexicon.CompoundsEndingWith(word) {
+			theme := c.Left + "___"
+			if !seen[theme] {
+				seen[theme] = true
+				byBlank[theme] = append(byBlank[theme], word)
+			}
+		}
+	}
+
+	themes := make([]string, 0, len(byBlank))
+	for theme := range byBlank {
+		themes = append(themes, theme)
+	}
+	sort.Strings(themes)
+
+	var candidates []Candidate
+	for _, theme := range themes {
+		members := byBlank[theme]
+		for _, quartet := range spreadQuartets(members, maxBucketCandidates) {
+			candidates = append(candidates, Candidate{
+				Words:      quartet,
+				Theme:      theme,
+				Confidence: bucketConfidence(0.65, len(members)),
+			})
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,92 @@
+package grouper
+
+import (
+	"connections/pkg/analyzer"
+	"fmt"
+	"sort"
+)
+
+// StrategyEdit is the name of the letter-manipulation strategy
+const StrategyEdit = "edit"
+
+// editThemes describes each transformation's group; %s is the category
+var editThemes = map[analyzer.EditKind]string{
+	analyzer.DropFirst:    "Drop the first letter to get %s",
+	analyzer.DropLast:     "Drop the last letter to get %s",
+	analyzer.AddLetter:    "Add a letter to get %s",
+	analyzer.ChangeLetter: "Change a letter to get %s",
+	analyzer.Reverse:      "Reversed %s",
+}
+
+// editConfidence is the base confidence for each transformation; adding or
+// changing any letter reaches far more words, so it proves less
+var editConfidence = map[analyzer.EditKind]float64{
+	analyzer.DropFirst:    0.6,
+	analyzer.DropLast:     0.6,
+	analyzer.AddLetter:    0.45,
+	analyzer.ChangeLetter: 0.4,
+	analyzer.Reverse:      0.65,
+}
+
+func init() {
+	Register(&editStrategy{analyzer: analyzer.New()})
+}
+
+// editStrategy finds words that the same one-letter transformation turns
+// into members of one lexicon category, e.g. BEAR, CHIP, SLEG, STOE losing
+// their first letter to become body parts
+type editStrategy struct {
+	analyzer *analyzer.Analyzer
+}
+
+func (s *editStrategy) Name() string { return StrategyEdit }
+
+func (s *editStrategy) FindGroups(words []string) []Candidate {
+	type bucket struct {
+		kind     analyzer.EditKind
+		category string
+	}
+
+	byBucket := make(map[bucket][]string)
+	for _, word := range words {
+		seen := make(map[bucket]bool)
+		for _, edit := range s.analyzer.DictionaryEdits(word) {
+			for _, category := range s.analyzer.Lexicon().CategoriesOf(edit.Result) {
+				b := bucket{kind: edit.Kind, category: category}
+				if !seen[b] {
+					seen[b] = true
+					byBucket[b] = append(byBucket[b], word)
+				}
+			}
+		}
+	}
+
+	order := make(map[analyzer.EditKind]int)
+	for i, kind := range analyzer.EditKinds {
+		order[kind] = i
+	}
+	buckets := make([]bucket, 0, len(byBucket))
+	for b := range byBucket {
+		buckets = append(buckets, b)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].kind != buckets[j].kind {
+			return order[buckets[i].kind] < order[buckets[j].kind]
+		}
+		return buckets[i].category < buckets[j].category
+	})
+
+	var candidates []Candidate
+	for _, b := range buckets {
+		members := byBucket[b]
+		for _, quartet := range spreadQuartets(members, maxBucketCandidates) {
+			candidates = append(candidates, Candidate{
+				Words:      quartet,
+				Theme:      fmt.Sprintf(editThemes[b.kind], b.category),
+				Confidence: bucketConfidence(editConfidence[b.kind], len(members)),
+			})
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,130 @@
+package grouper
+
+import (
+	"connections/pkg/embedding"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// StrategyEmbedding is the name of the word-embedding strategy
+const StrategyEmbedding = "embedding"
+
+const (
+	// minEmbeddingSimilarity is the lowest mean pairwise similarity worth proposing
+	minEmbeddingSimilarity = 0.3
+	// maxEmbeddingCandidates caps how many quartets the strategy proposes
+	maxEmbeddingCandidates = 20
+)
+
+// embeddingStrategy proposes the quartets whose word vectors are closest,
+// giving the pattern path semantic signal without network access
+type embeddingStrategy struct {
+	model *embedding.Model
+}
+
+// NewEmbeddingStrategy creates a strategy backed by a word-embedding model.
+// It needs a model file, so unlike the built-in strategies it is not
+// registered automatically; pass it to Register before creating Groupers.
+func NewEmbeddingStrategy(model *embedding.Model) Strategy {
+	return &embeddingStrategy{model: model}
+}
+
+func (s *embeddingStrategy) Name() string { return StrategyEmbedding }
+
+func (s *embeddingStrategy) FindGroups(words []string) []Candidate {
+	// Only words the model knows can be compared
+	var known []string
+	var vectors [][]float32
+	for _, word := range words {
+		if vector, ok := s.model.Vector(word); ok {
+			known = append(known, word)
+			vectors = append(vectors, vector)
+		}
+	}
+	if len(known) < 4 {
+		return nil
+	}
+
+	similarity := make([][]float64, len(known))
+	for i := range known {
+		similarity[i] = make([]float64, len(known))
+		for j := range known {
+			if i != j {
+				similarity[i][j] = embedding.Cosine(vectors[i], vectors[j])
+			}
+		}
+	}
+
+	type scored struct {
+		members [4]int
+		score   float64
+	}
+	var quartets []scored
+	n := len(known)
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			for c := b + 1; c < n; c++ {
+				for d := c + 1; d < n; d++ {
+					members := [4]int{a, b, c, d}
+					var total float64
+					for i := 0; i < 4; i++ {
+						for j := i + 1; j < 4; j++ {
+							total += similarity[members[i]][members[j]]
+						}
+					}
+					if score := total / 6; score >= minEmbeddingSimilarity {
+						quartets = append(quartets, scored{members: members, score: score})
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(quartets, func(i, j int) bool {
+		return quartets[i].score > quartets[j].score
+	})
+	if len(quartets) > maxEmbeddingCandidates {
+		quartets = quartets[:maxEmbeddingCandidates]
+	}
+
+	candidates := make([]Candidate, 0, len(quartets))
+	for _, q := range quartets {
+		group := make([]string, 4)
+		// The word closest to the rest names the group
+		central, best := 0, math.Inf(-1)
+		for i, member := range q.members {
+			group[i] = known[member]
+			var total float64
+			for _, other := range q.members {
+				total += similarity[member][other]
+			}
+			if total > best {
+				central, best = i, total
+			}
+		}
+		candidates = append(candidates, Candidate{
+			Words:      group,
+			Theme:      "Related to " + group[central],
+			Confidence: embeddingConfidence(q.score),
+		})
+	}
+	return candidates
+}
+
+// embeddingConfidence maps a mean cosine similarity to a confidence. Even
+// tight clusters can be red herrings, so it stays below the lexicon's.
+func embeddingConfidence(similarity float64) float64 {
+	return math.Min(0.75, 0.2+0.8*(similarity-minEmbeddingSimilarity))
+}
+
+// RegisterEmbeddings loads a word-embedding model, quantized or GloVe text,
+// and registers the embedding strategy backed by it
+func RegisterEmbeddings(path string) error {
+	model, err := embedding.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load embeddings: %w", err)
+	}
+	Register(NewEmbeddingStrategy(model))
+	return nil
+}
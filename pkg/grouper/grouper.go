@@ -2,6 +2,7 @@ package grouper
 
 import (
 	"connections/pkg/analyzer"
+	"connections/pkg/normalize"
 	"sort"
 	"strings"
 )
@@ -11,97 +12,225 @@ type Candidate struct {
 	Words      []string
 	Theme      string
 	Confidence float64
+	Strategy   string // Name of the strategy that proposed the group
+}
+
+// Names of the built-in strategies
+const (
+	StrategyPrefix   = "prefix"
+	StrategySuffix   = "suffix"
+	StrategyLength   = "length"
+	StrategyCompound = "compound"
+)
+
+func init() {
+	Register(StrategyFunc(StrategyPrefix, findPrefixGroups))
+	Register(StrategyFunc(StrategySuffix, findSuffixGroups))
+	Register(StrategyFunc(StrategyLength, findLengthGroups))
+}
+
+// weightedStrategy is an enabled strategy and the weight applied to its confidences
+type weightedStrategy struct {
+	strategy Strategy
+	weight   float64
 }
 
 // Grouper finds potential groupings of words
 type Grouper struct {
-	analyzer *analyzer.Analyzer
+	analyzer   *analyzer.Analyzer
+	strategies []weightedStrategy
 }
 
-// New creates a new Grouper instance
+// New creates a new Grouper instance using every registered strategy
 func New() *Grouper {
-	return &Grouper{
+	return NewWithConfig(Config{})
+}
+
+// NewWithConfig creates a Grouper using the registered strategies that the
+// config leaves enabled, weighted as configured
+func NewWithConfig(cfg Config) *Grouper {
+	g := &Grouper{
 		analyzer: analyzer.New(),
 	}
+
+	for _, strategy := range registeredStrategies() {
+		sc := cfg.Strategies[strategy.Name()]
+		if !sc.enabled() {
+			continue
+		}
+		g.strategies = append(g.strategies, weightedStrategy{
+			strategy: strategy,
+			weight:   sc.weight(),
+		})
+	}
+
+	return g
 }
 
 // FindGroups analyzes words and returns potential groupings sorted by confidence
 func (g *Grouper) FindGroups(words []string) []Candidate {
 	var candidates []Candidate
 
-	// Strategy 1: Find words with common prefixes
-	candidates = append(candidates, g.findPrefixGroups(words)...)
-
-	// Strategy 2: Find words with common suffixes
-	candidates = append(candidates, g.findSuffixGroups(words)...)
-
-	// Strategy 3: Find words by length patterns
-	candidates = append(candidates, g.findLengthGroups(words)...)
-
-	// Strategy 4: Find compound word parts
-	candidates = append(candidates, g.findCompoundGroups(words)...)
+	for _, ws := range g.strategies {
+		for _, candidate := range ws.strategy.FindGroups(words) {
+			candidate.Strategy = ws.strategy.Name()
+			candidate.Confidence *= ws.weight
+			if candidate.Confidence > 1 {
+				candidate.Confidence = 1
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
 
 	// Sort by confidence (highest first)
-	sort.Slice(candidates, func(i, j int) bool {
+	sort.SliceStable(candidates, func(i, j int) bool {
 		return candidates[i].Confidence > candidates[j].Confidence
 	})
 
 	return candidates
 }
 
-func (g *Grouper) findPrefixGroups(words []string) []Candidate {
-	prefixMap := make(map[string][]string)
+// Affix lengths the prefix and suffix strategies try
+const (
+	minAffixLength = 2
+	maxAffixLength = 6
+)
 
-	for _, word := range words {
-		if len(word) >= 3 {
-			prefix := strings.ToLower(word[:3])
-			prefixMap[prefix] = append(prefixMap[prefix], word)
-		}
+const (
+	// maxBucketCandidates caps how many quartets any strategy proposes for
+	// one bucket of matching words; a board of 16 rhymes or 16 animals
+	// would otherwise offer all 1820
+	maxBucketCandidates = 20
+	// maxWeakAffixBucket is the largest bucket a 2-letter or common affix
+	// is proposed for; beyond it sharing the affix is close to chance
+	maxWeakAffixBucket = 6
+)
+
+// commonPrefixes and commonSuffixes are everyday English affixes; sharing
+// one says little about a group, so they score lower than rarer affixes
+var (
+	commonPrefixes = map[string]bool{
+		"re": true, "un": true, "in": true, "im": true, "de": true, "dis": true,
+		"en": true, "non": true, "pre": true, "pro": true, "con": true, "com": true,
+		"ex": true, "mis": true, "over": true, "sub": true, "inter": true, "trans": true,
+	}
+	commonSuffixes = map[string]bool{
+		"ed": true, "er": true, "es": true, "ly": true, "al": true, "ing": true,
+		"ers": true, "est": true, "ion": true, "ity": true, "ment": true, "ness": true,
+		"ous": true, "ful": true, "less": true, "able": true, "ible": true, "tion": true,
+		"sion": true, "ive": true, "ent": true, "ant": true,
 	}
+)
 
+func findPrefixGroups(words []string) []Candidate {
+	return findAffixGroups(words, false)
+}
+
+func findSuffixGroups(words []string) []Candidate {
+	return findAffixGroups(words, true)
+}
+
+// findAffixGroups buckets words by every prefix (or suffix) length and
+// proposes quartets from each bucket, capped per bucket. Longer affixes are
+// tried first, so a quartet is proposed once, under the most specific affix
+// it shares. Weak affixes are skipped for large buckets.
+func findAffixGroups(words []string, suffix bool) []Candidate {
 	var candidates []Candidate
-	for prefix, group := range prefixMap {
-		if len(group) >= 4 {
-			candidates = append(candidates, Candidate{
-				Words:      group[:4],
-				Theme:      "Words starting with '" + prefix + "'",
-				Confidence: 0.5,
-			})
+	seen := make(map[string]bool)
+
+	for length := maxAffixLength; length >= minAffixLength; length-- {
+		buckets := make(map[string][]string)
+		var affixes []string
+		for _, word := range words {
+			letters := []rune(strings.ToLower(normalize.Letters(word)))
+			if len(letters) < length {
+				continue
+			}
+			affix := string(letters[:length])
+			if suffix {
+				affix = string(letters[len(letters)-length:])
+			}
+			if _, ok := buckets[affix]; !ok {
+				affixes = append(affixes, affix)
+			}
+			buckets[affix] = append(buckets[affix], word)
+		}
+
+		for _, affix := range affixes {
+			group := buckets[affix]
+			if len(group) < 4 || (len(group) > maxWeakAffixBucket && weakAffix(affix, suffix)) {
+				continue
+			}
+			confidence := bucketConfidence(affixConfidence(affix, suffix), len(group))
+			for _, quartet := range spreadQuartets(group, maxBucketCandidates) {
+				key := strings.Join(quartet, "|")
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				candidates = append(candidates, Candidate{
+					Words:      quartet,
+					Theme:      affixTheme(affix, suffix),
+					Confidence: confidence,
+				})
+			}
 		}
 	}
 
 	return candidates
 }
 
-func (g *Grouper) findSuffixGroups(words []string) []Candidate {
-	suffixMap := make(map[string][]string)
+// affixConfidence prefers longer and rarer affixes: four words starting
+// with "sh" is likely chance, four starting with "stra" is not
+func affixConfidence(affix string, suffix bool) float64 {
+	var confidence float64
+	switch n := len([]rune(affix)); {
+	case n <= 2:
+		confidence = 0.35
+	case n == 3:
+		confidence = 0.5
+	case n == 4:
+		confidence = 0.6
+	default:
+		confidence = 0.65
+	}
 
-	for _, word := range words {
-		if len(word) >= 3 {
-			suffix := strings.ToLower(word[len(word)-3:])
-			suffixMap[suffix] = append(suffixMap[suffix], word)
-		}
+	common := commonPrefixes
+	if suffix {
+		common = commonSuffixes
 	}
+	if common[affix] {
+		confidence -= 0.1
+	}
+	return confidence
+}
 
-	var candidates []Candidate
-	for suffix, group := range suffixMap {
-		if len(group) >= 4 {
-			candidates = append(candidates, Candidate{
-				Words:      group[:4],
-				Theme:      "Words ending with '" + suffix + "'",
-				Confidence: 0.5,
-			})
-		}
+// weakAffix reports whether an affix is too short or too common to mean
+// much on its own
+func weakAffix(affix string, suffix bool) bool {
+	if len([]rune(affix)) <= 2 {
+		return true
 	}
+	if suffix {
+		return commonSuffixes[affix]
+	}
+	return commonPrefixes[affix]
+}
 
-	return candidates
+// affixTheme describes a shared prefix or suffix
+func affixTheme(affix string, suffix bool) string {
+	if suffix {
+		return "Words ending with '" + affix + "'"
+	}
+	return "Words starting with '" + affix + "'"
 }
 
-func (g *Grouper) findLengthGroups(words []string) []Candidate {
+func findLengthGroups(words []string) []Candidate {
 	lengthMap := make(map[int][]string)
 
 	for _, word := range words {
-		lengthMap[len(word)] = append(lengthMap[len(word)], word)
+		length := normalize.Length(word)
+		lengthMap[length] = append(lengthMap[length], word)
 	}
 
 	var candidates []Candidate
@@ -118,33 +247,58 @@ func (g *Grouper) findLengthGroups(words []string) []Candidate {
 	return candidates
 }
 
-func (g *Grouper) findCompoundGroups(words []string) []Candidate {
-	// Look for words that could be parts of compound words
-	// e.g., BLUE, BIRD could both go with "BERRY" -> BLUEBERRY, BLACKBIRD
+// spreadQuartets returns at most limit 4-word combinations of words. When
+// there are more, it takes disjoint runs of consecutive words first, so the
+// bucket can still fill a whole partition, then evenly spaced ones from the
+// full list, so a large bucket cannot flood the partition search.
+func spreadQuartets(words []string, limit int) [][]string {
+	all := quartets(words)
+	if len(all) <= limit {
+		return all
+	}
 
-	// This is a placeholder for more sophisticated compound word detection
-	var candidates []Candidate
+	var spread [][]string
+	seen := make(map[string]bool)
+	add := func(quartet []string) {
+		key := strings.Join(quartet, "|")
+		if len(spread) < limit && !seen[key] {
+			seen[key] = true
+			spread = append(spread, quartet)
+		}
+	}
 
-	// Common compound word patterns
-	commonParts := []string{"FIRE", "WATER", "SNOW", "SUPER", "OVER", "UNDER"}
+	for i := 0; i+4 <= len(words); i += 4 {
+		add(append([]string(nil), words[i:i+4]...))
+	}
+	for i := 0; len(spread) < limit && i < limit; i++ {
+		add(all[i*len(all)/limit])
+	}
+	return spread
+}
 
-	for _, part := range commonParts {
-		var matching []string
-		for _, word := range words {
-			upper := strings.ToUpper(word)
-			if strings.Contains(upper, part) {
-				matching = append(matching, word)
+// quartets returns every 4-word combination of words, in input order
+func quartets(words []string) [][]string {
+	var result [][]string
+	n := len(words)
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			for c := b + 1; c < n; c++ {
+				for d := c + 1; d < n; d++ {
+					result = append(result, []string{words[a], words[b], words[c], words[d]})
+				}
 			}
 		}
-
-		if len(matching) >= 4 {
-			candidates = append(candidates, Candidate{
-				Words:      matching[:4],
-				Theme:      "Related to '" + part + "'",
-				Confidence: 0.4,
-			})
-		}
 	}
+	return result
+}
 
-	return candidates
+// bucketConfidence lowers a strategy's base confidence when more than four
+// words share a feature, since the extra words are likely red herrings and
+// any particular quartet is less likely to be the intended one
+func bucketConfidence(base float64, size int) float64 {
+	confidence := base - 0.05*float64(size-4)
+	if confidence < 0.2 {
+		return 0.2
+	}
+	return confidence
 }
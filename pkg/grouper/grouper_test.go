@@ -0,0 +1,396 @@
+package grouper
+
+import (
+	"connections/pkg/embedding"
+	"connections/pkg/lexicon"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisteredStrategies(t *testing.T) {
+	names := Strategies()
+	for _, want := range []string{StrategyPrefix, StrategySuffix, StrategyLength, StrategyCompound} {
+		found := false
+		for _, name := range names {
+			if name == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected built-in strategy %q to be registered, got %v", want, names)
+		}
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic registering a duplicate strategy")
+		}
+	}()
+	Register(StrategyFunc(StrategyPrefix, func([]string) []Candidate { return nil }))
+}
+
+func TestNewWithConfig(t *testing.T) {
+	words := []string{"BOX", "SKY", "ANY", "EGO"}
+
+	disabled := false
+	half := 0.5
+	g := NewWithConfig(Config{Strategies: map[string]StrategyConfig{
+		StrategyLength: {Weight: &half},
+		StrategyPrefix: {Enabled: &disabled},
+	}})
+
+	candidates := g.FindGroups(words)
+	if len(candidates) != 1 {
+		t.Fatalf("expected 1 candidate, got %d: %+v", len(candidates), candidates)
+	}
+	if candidates[0].Strategy != StrategyLength {
+		t.Errorf("expected strategy %q, got %q", StrategyLength, candidates[0].Strategy)
+	}
+	if candidates[0].Confidence != 0.15 {
+		t.Errorf("expected weighted confidence 0.15, got %v", candidates[0].Confidence)
+	}
+
+	off := NewWithConfig(Config{Strategies: map[string]StrategyConfig{
+		StrategyLength: {Enabled: &disabled},
+	}})
+	if got := off.FindGroups(words); len(got) != 0 {
+		t.Errorf("expected no candidates with length disabled, got %+v", got)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(valid, []byte(`{"strategies": {"length": {"enabled": false}, "prefix": {"weight": 0.8}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(valid)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Strategies[StrategyLength].enabled() {
+		t.Error("expected length strategy to be disabled")
+	}
+	if cfg.Strategies[StrategyPrefix].weight() != 0.8 {
+		t.Errorf("expected prefix weight 0.8, got %v", cfg.Strategies[StrategyPrefix].weight())
+	}
+
+	unknown := filepath.Join(dir, "unknown.json")
+	if err := os.WriteFile(unknown, []byte(`{"strategies": {"nope": {"enabled": false}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(unknown); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestAnagramStrategy(t *testing.T) {
+	words := []string{
+		"LUMP", "MILE", "REAP", "AMONG",
+		"CAT", "DOG", "EMU", "YAK",
+	}
+
+	g := New()
+	found := false
+	for _, candidate := range g.FindGroups(words) {
+		if candidate.Strategy == StrategyAnagram && candidate.Theme == "Anagrams of fruits" {
+			found = true
+			for _, word := range candidate.Words {
+				if word == "CAT" || word == "DOG" || word == "EMU" || word == "YAK" {
+					t.Errorf("unexpected word %q in anagram group", word)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Error("expected an 'Anagrams of fruits' candidate")
+	}
+}
+
+func TestQuartets(t *testing.T) {
+	if got := len(quartets([]string{"A", "B", "C", "D", "E"})); got != 5 {
+		t.Errorf("expected 5 quartets from 5 words, got %d", got)
+	}
+	if got := len(quartets([]string{"A", "B", "C"})); got != 0 {
+		t.Errorf("expected no quartets from 3 words, got %d", got)
+	}
+}
+
+func TestHiddenWordStrategy(t *testing.T) {
+	words := []string{
+		"SCOWL", "CATALOG", "TOP IGLOO", "SPRAT",
+		"QUIZ", "JUMP", "FIZZ", "VIVID",
+	}
+
+	found := false
+	for _, candidate := range New().FindGroups(words) {
+		if candidate.Strategy == StrategyHidden && candidate.Theme == "Hidden animals" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a 'Hidden animals' candidate")
+	}
+}
+
+func TestCompoundStrategy(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		theme string
+	}{
+		{
+			name:  "blank after",
+			words: []string{"FOOT", "BASKET", "EYE", "MEAT", "CAT", "EMU", "YAK", "GNU"},
+			theme: "___BALL",
+		},
+		{
+			name:  "blank before",
+			words: []string{"ALARM", "PLACE", "WORK", "TRUCK", "CAT", "EMU", "YAK", "GNU"},
+			theme: "FIRE___",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := false
+			for _, candidate := range New().FindGroups(tt.words) {
+				if candidate.Strategy == StrategyCompound && candidate.Theme == tt.theme {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a %q candidate", tt.theme)
+			}
+		})
+	}
+}
+
+func TestHomophoneStrategy(t *testing.T) {
+	tests := []struct {
+		words []string
+		theme string
+	}{
+		{[]string{"SEA", "WHY", "TEA", "EYE", "DOG", "FROG", "LOG", "FOG"}, "Sound like letters"},
+		{[]string{"WON", "TOO", "FOR", "ATE", "DOG", "FROG", "LOG", "FOG"}, "Sound like numbers"},
+	}
+
+	for _, tt := range tests {
+		found := false
+		for _, candidate := range New().FindGroups(tt.words) {
+			if candidate.Strategy == StrategyHomophone && candidate.Theme == tt.theme {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a %q candidate for %v", tt.theme, tt.words)
+		}
+	}
+}
+
+func TestRhymeStrategy(t *testing.T) {
+	tests := []struct {
+		words []string
+		theme string
+	}{
+		{[]string{"CAT", "BAT", "HAT", "RAT", "BANANA", "APPLE", "PICKLE", "CHERRY"}, "Rhymes with CAT"},
+		{[]string{"CAT", "DOG", "TREE", "SHIP", "BANANA", "APPLE", "PICKLE", "CHERRY"}, "One-syllable words"},
+	}
+
+	for _, tt := range tests {
+		found := false
+		for _, candidate := range New().FindGroups(tt.words) {
+			if candidate.Strategy == StrategyRhyme && candidate.Theme == tt.theme {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a %q candidate for %v", tt.theme, tt.words)
+		}
+	}
+}
+
+func TestEditStrategy(t *testing.T) {
+	words := []string{"BEAR", "CHIP", "SLEG", "STOE", "TAR", "GOD", "WOC", "TAB"}
+
+	tests := []struct {
+		theme string
+		words map[string]bool
+	}{
+		{"Drop the first letter to get body parts", map[string]bool{"BEAR": true, "CHIP": true, "SLEG": true, "STOE": true}},
+		{"Reversed animals", map[string]bool{"TAR": true, "GOD": true, "WOC": true, "TAB": true}},
+	}
+
+	candidates := New().FindGroups(words)
+	for _, tt := range tests {
+		found := false
+		for _, candidate := range candidates {
+			if candidate.Strategy != StrategyEdit || candidate.Theme != tt.theme {
+				continue
+			}
+			found = true
+			for _, word := range candidate.Words {
+				if !tt.words[word] {
+					t.Errorf("unexpected word %q in %q group", word, tt.theme)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("expected a %q candidate", tt.theme)
+		}
+	}
+}
+
+func TestCategoryStrategy(t *testing.T) {
+	words := []string{"MARS", "VENUS", "EARTH", "SATURN", "ALPHA", "JETS", "TROUT", "OAK"}
+
+	found := false
+	for _, candidate := range New().FindGroups(words) {
+		if candidate.Strategy == StrategyCategory && candidate.Theme == "Planets" {
+			found = true
+			if candidate.Confidence < 0.7 {
+				t.Errorf("expected a specific category to be confident, got %.2f", candidate.Confidence)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected a 'Planets' candidate")
+	}
+
+	if categoryConfidence(8) <= categoryConfidence(40) {
+		t.Error("expected smaller categories to score higher")
+	}
+}
+
+func TestCategoryStrategyOneCategoryBoard(t *testing.T) {
+	words := []string{
+		"BEAR", "LION", "TIGER", "WOLF", "PIG", "RAT", "OX", "ANT",
+		"COW", "HEN", "ELK", "EMU", "YAK", "APE", "BAT", "BEE",
+	}
+
+	covered := make(map[string]bool)
+	count := 0
+	for _, candidate := range (&categoryStrategy{lexicon: lexicon.Default()}).FindGroups(words) {
+		if candidate.Theme != "Animals" {
+			continue
+		}
+		count++
+		for _, word := range candidate.Words {
+			covered[word] = true
+		}
+	}
+
+	if count == 0 || count > maxBucketCandidates {
+		t.Errorf("expected 1-%d Animals candidates, got %d", maxBucketCandidates, count)
+	}
+	if len(covered) != len(words) {
+		t.Errorf("expected every animal in some candidate, got %d of %d", len(covered), len(words))
+	}
+}
+
+func TestOversizedBucketsCapped(t *testing.T) {
+	// Every word rhymes, is one letter from the others and has 3 letters
+	words := []string{
+		"CAT", "BAT", "RAT", "HAT", "MAT", "PAT", "SAT", "FAT",
+		"VAT", "OAT", "EAT", "TAT", "GAT", "KAT", "LAT", "NAT",
+	}
+
+	perTheme := make(map[string]int)
+	for _, candidate := range New().FindGroups(words) {
+		perTheme[candidate.Strategy+": "+candidate.Theme]++
+	}
+	for theme, count := range perTheme {
+		if count > maxBucketCandidates {
+			t.Errorf("%s proposed %d quartets, want at most %d", theme, count, maxBucketCandidates)
+		}
+	}
+}
+
+func TestEmbeddingStrategy(t *testing.T) {
+	model := embedding.New(2)
+	vectors := map[string][]float32{
+		"salmon": {1, 0.1}, "trout": {1, 0.05}, "cod": {0.95, 0.1}, "pike": {1, 0},
+		"red": {0.1, 1}, "blue": {0, 1}, "green": {0.05, 1}, "pink": {0.1, 0.95},
+	}
+	for word, vector := range vectors {
+		if err := model.Add(word, vector); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	words := []string{"SALMON", "RED", "TROUT", "BLUE", "COD", "GREEN", "PIKE", "PINK", "UNKNOWN"}
+	candidates := NewEmbeddingStrategy(model).FindGroups(words)
+	if len(candidates) < 2 {
+		t.Fatalf("expected the two clusters, got %+v", candidates)
+	}
+
+	for _, candidate := range candidates[:2] {
+		fish := 0
+		for _, word := range candidate.Words {
+			switch word {
+			case "SALMON", "TROUT", "COD", "PIKE":
+				fish++
+			}
+		}
+		if fish != 0 && fish != 4 {
+			t.Errorf("expected the best quartets to be whole clusters, got %v", candidate.Words)
+		}
+		if candidate.Confidence <= 0.5 {
+			t.Errorf("expected a tight cluster to be confident, got %.2f", candidate.Confidence)
+		}
+	}
+}
+
+func TestAffixGroups(t *testing.T) {
+	// Five words share "star"; every quartet must be offered, not just the first four
+	words := []string{"STARFISH", "STARLING", "STARDUST", "STARBOARD", "STARK", "APPLE", "MELON", "GRAPE"}
+
+	var starts []Candidate
+	for _, candidate := range findPrefixGroups(words) {
+		if candidate.Theme == "Words starting with 'star'" {
+			starts = append(starts, candidate)
+		}
+		if candidate.Theme == "Words starting with 'sta'" || candidate.Theme == "Words starting with 'st'" {
+			t.Errorf("expected quartets under the longest shared prefix only, got %q", candidate.Theme)
+		}
+	}
+	if len(starts) != 5 {
+		t.Fatalf("expected 5 quartets from 5 words starting with 'star', got %d", len(starts))
+	}
+	if starts[0].Confidence >= affixConfidence("star", false) {
+		t.Errorf("expected an oversized bucket to lower confidence, got %.2f", starts[0].Confidence)
+	}
+
+	suffixes := findSuffixGroups([]string{"WALKING", "TALKING", "SINGING", "RUNNING", "CAT"})
+	if len(suffixes) != 1 || suffixes[0].Theme != "Words ending with 'ing'" {
+		t.Fatalf("expected one 'ing' quartet, got %+v", suffixes)
+	}
+
+	if affixConfidence("ing", true) >= affixConfidence("ish", true) {
+		t.Error("expected a common suffix to score below a rarer one")
+	}
+	if affixConfidence("st", false) >= affixConfidence("star", false) {
+		t.Error("expected a longer prefix to score higher")
+	}
+
+	// Sixteen words sharing "str": the bucket is capped and the 2-letter
+	// "st" bucket, near-chance at this size, is not proposed at all
+	strWords := []string{
+		"STRAW", "STRAP", "STREAM", "STREET", "STRIKE", "STRING", "STRIPE", "STROKE",
+		"STRONG", "STRUCK", "STRAND", "STRESS", "STRICT", "STRIDE", "STROLL", "STRUT",
+	}
+	byTheme := make(map[string]int)
+	for _, candidate := range findPrefixGroups(strWords) {
+		byTheme[candidate.Theme]++
+	}
+	if n := byTheme["Words starting with 'str'"]; n == 0 || n > maxBucketCandidates {
+		t.Errorf("expected 1-%d 'str' quartets, got %d", maxBucketCandidates, n)
+	}
+	if byTheme["Words starting with 'st'"] != 0 {
+		t.Error("expected no quartets for a 2-letter prefix shared by 16 words")
+	}
+}
@@ -0,0 +1,56 @@
+package grouper
+
+import (
+	"connections/pkg/analyzer"
+	"sort"
+)
+
+// StrategyHidden is the name of the hidden-word strategy
+const StrategyHidden = "hidden"
+
+func init() {
+	Register(&hiddenWordStrategy{analyzer: analyzer.New()})
+}
+
+// hiddenWordStrategy finds entries that each hide a member of the same
+// lexicon category, e.g. BREAD, SCOWL, TOP IGLOO, SPRAT hiding animals
+type hiddenWordStrategy struct {
+	analyzer *analyzer.Analyzer
+}
+
+func (s *hiddenWordStrategy) Name() string { return StrategyHidden }
+
+func (s *hiddenWordStrategy) FindGroups(words []string) []Candidate {
+	byCategory := make(map[string][]string)
+	for _, word := range words {
+		seen := make(map[string]bool)
+		for _, hidden := range s.analyzer.HiddenWords(word) {
+			for _, category := range s.analyzer.Lexicon().CategoriesOf(hidden) {
+				if !seen[category] {
+					seen[category] = true
+					byCategory[category] = append(byCategory[category], word)
+				}
+			}
+		}
+	}
+
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var candidates []Candidate
+	for _, category := range categories {
+		members := byCategory[category]
+		for _, quartet := range spreadQuartets(members, maxBucketCandidates) {
+			candidates = append(candidates, Candidate{
+				Words:      quartet,
+				Theme:      "Hidden " + category,
+				Confidence: bucketConfidence(0.55, len(members)),
+			})
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,106 @@
+package grouper
+
+import (
+	"connections/pkg/analyzer"
+	"connections/pkg/normalize"
+	"sort"
+	"sync"
+)
+
+// StrategyHomophone is the name of the sound-alike strategy
+const StrategyHomophone = "homophone"
+
+// minMetaphoneWordLength keeps very short words out of the Metaphone
+// fallback, where their keys collide too easily
+const minMetaphoneWordLength = 3
+
+func init() {
+	Register(&homophoneStrategy{analyzer: analyzer.New()})
+}
+
+// homophoneStrategy finds entries that sound like members of one lexicon
+// category, such as letters (SEA, WHY, TEA, EYE) or numbers (WON, TOO,
+// FOR, ATE). Words in the pronunciation table are matched exactly; other
+// words fall back to their Metaphone key.
+type homophoneStrategy struct {
+	analyzer *analyzer.Analyzer
+
+	metaphoneOnce  sync.Once
+	metaphoneIndex map[string][]string // Metaphone key -> category words
+}
+
+func (s *homophoneStrategy) Name() string { return StrategyHomophone }
+
+func (s *homophoneStrategy) FindGroups(words []string) []Candidate {
+	lex := s.analyzer.Lexicon()
+
+	byCategory := make(map[string][]string)
+	for _, word := range words {
+		var soundAlikes []string
+		if _, ok := s.analyzer.Pronunciation(word); ok {
+			soundAlikes = s.analyzer.Homophones(word)
+		} else {
+			soundAlikes = s.metaphoneMatches(word)
+		}
+
+		seen := make(map[string]bool)
+		for _, other := range soundAlikes {
+			for _, category := range lex.CategoriesOf(other) {
+				if !seen[category] {
+					seen[category] = true
+					byCategory[category] = append(byCategory[category], word)
+				}
+			}
+		}
+	}
+
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var candidates []Candidate
+	for _, category := range categories {
+		members := byCategory[category]
+		for _, quartet := range spreadQuartets(members, maxBucketCandidates) {
+			candidates = append(candidates, Candidate{
+				Words:      quartet,
+				Theme:      "Sound like " + category,
+				Confidence: bucketConfidence(0.65, len(members)),
+			})
+		}
+	}
+
+	return candidates
+}
+
+// metaphoneMatches returns category words with the same Metaphone key
+func (s *homophoneStrategy) metaphoneMatches(word string) []string {
+	s.metaphoneOnce.Do(func() {
+		s.metaphoneIndex = make(map[string][]string)
+		for _, member := range s.analyzer.Lexicon().AllWords() {
+			if len(member) >= minMetaphoneWordLength {
+				key := s.analyzer.Metaphone(member)
+				s.metaphoneIndex[key] = append(s.metaphoneIndex[key], member)
+			}
+		}
+	})
+
+	if normalize.Length(word) < minMetaphoneWordLength {
+		return nil
+	}
+
+	var matches []string
+	for _, member := range s.metaphoneIndex[s.analyzer.Metaphone(word)] {
+		if member != upper(word) {
+			matches = append(matches, member)
+		}
+	}
+	return matches
+}
+
+// upper normalizes a word for comparison with lexicon entries
+func upper(word string) string {
+	return normalize.Canonical(word)
+}
@@ -0,0 +1,77 @@
+package grouper
+
+import (
+	"connections/pkg/analyzer"
+	"fmt"
+)
+
+// StrategyRhyme is the name of the rhyme and syllable strategy
+const StrategyRhyme = "rhyme"
+
+func init() {
+	Register(&rhymeStrategy{analyzer: analyzer.New()})
+}
+
+// rhymeStrategy groups words that rhyme with each other and, like the
+// length strategy does for spelling, words with the same syllable count
+type rhymeStrategy struct {
+	analyzer *analyzer.Analyzer
+}
+
+func (s *rhymeStrategy) Name() string { return StrategyRhyme }
+
+func (s *rhymeStrategy) FindGroups(words []string) []Candidate {
+	var candidates []Candidate
+
+	// Words that rhyme, in order of first appearance
+	byRhyme := make(map[string][]string)
+	var keys []string
+	for _, word := range words {
+		key, ok := s.analyzer.RhymeKey(word)
+		if !ok {
+			continue
+		}
+		if _, seen := byRhyme[key]; !seen {
+			keys = append(keys, key)
+		}
+		byRhyme[key] = append(byRhyme[key], word)
+	}
+
+	for _, key := range keys {
+		members := byRhyme[key]
+		for _, quartet := range spreadQuartets(members, maxBucketCandidates) {
+			candidates = append(candidates, Candidate{
+				Words:      quartet,
+				Theme:      "Rhymes with " + quartet[0],
+				Confidence: bucketConfidence(0.6, len(members)),
+			})
+		}
+	}
+
+	// Exactly four words sharing a syllable count
+	bySyllables := make(map[int][]string)
+	for _, word := range words {
+		count := s.analyzer.Syllables(word)
+		bySyllables[count] = append(bySyllables[count], word)
+	}
+
+	for count, group := range bySyllables {
+		if len(group) == 4 {
+			candidates = append(candidates, Candidate{
+				Words:      group,
+				Theme:      syllableTheme(count),
+				Confidence: 0.3,
+			})
+		}
+	}
+
+	return candidates
+}
+
+// syllableTheme describes a syllable-count group
+func syllableTheme(count int) string {
+	if count == 1 {
+		return "One-syllable words"
+	}
+	return fmt.Sprintf("%d-syllable words", count)
+}
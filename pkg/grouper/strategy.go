@@ -0,0 +1,125 @@
+package grouper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Strategy proposes candidate groups for a set of words. Strategies are
+// registered by name, usually from an init function, and every Grouper
+// created afterwards uses them unless its config disables them.
+type Strategy interface {
+	Name() string
+	FindGroups(words []string) []Candidate
+}
+
+// funcStrategy adapts a plain function to the Strategy interface
+type funcStrategy struct {
+	name string
+	find func(words []string) []Candidate
+}
+
+func (s funcStrategy) Name() string { return s.name }
+
+func (s funcStrategy) FindGroups(words []string) []Candidate { return s.find(words) }
+
+// StrategyFunc wraps a function as a named Strategy
+func StrategyFunc(name string, find func(words []string) []Candidate) Strategy {
+	return funcStrategy{name: name, find: find}
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Strategy
+)
+
+// Register makes a strategy available to new Groupers. It panics if the
+// strategy is nil or a strategy with the same name is already registered.
+func Register(strategy Strategy) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if strategy == nil {
+		panic("grouper: Register strategy is nil")
+	}
+	for _, existing := range registry {
+		if existing.Name() == strategy.Name() {
+			panic("grouper: Register called twice for strategy " + strategy.Name())
+		}
+	}
+	registry = append(registry, strategy)
+}
+
+// Strategies returns the sorted names of the registered strategies
+func Strategies() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for _, strategy := range registry {
+		names = append(names, strategy.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registeredStrategies returns the registered strategies in registration order
+func registeredStrategies() []Strategy {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]Strategy(nil), registry...)
+}
+
+// StrategyConfig enables or weights a single strategy. Unset fields keep
+// the defaults: enabled, with weight 1.
+type StrategyConfig struct {
+	Enabled *bool    `json:"enabled,omitempty"`
+	Weight  *float64 `json:"weight,omitempty"`
+}
+
+func (sc StrategyConfig) enabled() bool {
+	return sc.Enabled == nil || *sc.Enabled
+}
+
+func (sc StrategyConfig) weight() float64 {
+	if sc.Weight == nil || *sc.Weight < 0 {
+		return 1
+	}
+	return *sc.Weight
+}
+
+// Config selects and weights strategies by name
+type Config struct {
+	Strategies map[string]StrategyConfig `json:"strategies"`
+}
+
+// LoadConfig reads a JSON config such as
+//
+//	{"strategies": {"length": {"enabled": false}, "prefix": {"weight": 0.8}}}
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read grouper config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse grouper config: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, name := range Strategies() {
+		known[name] = true
+	}
+	for name := range cfg.Strategies {
+		if !known[name] {
+			return Config{}, fmt.Errorf("grouper config names unknown strategy %q", name)
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,288 @@
+package lexicon
+
+import (
+	"bufio"
+	"connections/pkg/normalize"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Category files live in data/categories, one upper-case entry per line.
+// The file name (without .txt, underscores for spaces) is the category name,
+// keeping its case so proper nouns read naturally ("NFL_teams.txt" is
+// "NFL teams"), and lines starting with '#' are comments.
+//
+//go:embed data/categories/*.txt
+var categoryFiles embed.FS
+
+// compoundFile lists compound words and phrases as "LEFT RIGHT" lines
+//
+//go:embed data/compounds.txt
+var compoundFile string
+
+// pronunciationFile lists ARPAbet pronunciations as "WORD  PH ON EMES" lines
+//
+//go:embed data/pronunciations.txt
+var pronunciationFile string
+
+// versionFile holds the version of the embedded data
+//
+//go:embed data/version.txt
+var versionFile string
+
+// Compound is a known compound word or phrase split into its two parts
+type Compound struct {
+	Left  string
+	Right string
+}
+
+// Lexicon is a set of named word categories with reverse lookup
+type Lexicon struct {
+	version    string
+	names      []string
+	categories map[string][]string
+	byWord     map[string][]string
+
+	// Compounds indexed by each part
+	byLeft  map[string][]Compound
+	byRight map[string][]Compound
+
+	// Pronunciations, and words indexed by their stress-free pronunciation
+	pronunciations map[string][]string
+	bySound        map[string][]string
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultLexicon *Lexicon
+)
+
+// Default returns the lexicon built from the embedded category and compound files
+func Default() *Lexicon {
+	defaultOnce.Do(func() {
+		l, err := load()
+		if err != nil {
+			// The files are embedded at build time, so this is a programming error
+			panic(err)
+		}
+		defaultLexicon = l
+	})
+	return defaultLexicon
+}
+
+// New builds a lexicon from category names to member words
+func New(categories map[string][]string) *Lexicon {
+	l := &Lexicon{
+		categories: make(map[string][]string),
+		byWord:     make(map[string][]string),
+		byLeft:     make(map[string][]Compound),
+		byRight:    make(map[string][]Compound),
+
+		pronunciations: make(map[string][]string),
+		bySound:        make(map[string][]string),
+	}
+	for name, words := range categories {
+		l.add(name, words)
+	}
+	sort.Strings(l.names)
+	return l
+}
+
+// load reads the embedded category files
+func load() (*Lexicon, error) {
+	entries, err := categoryFiles.ReadDir("data/categories")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	categories := make(map[string][]string)
+	for _, entry := range entries {
+		file := path.Join("data/categories", entry.Name())
+		data, err := categoryFiles.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		name := strings.ReplaceAll(strings.TrimSuffix(entry.Name(), ".txt"), "_", " ")
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			categories[name] = append(categories[name], line)
+		}
+	}
+
+	l := New(categories)
+	scanner := bufio.NewScanner(strings.NewReader(versionFile))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			l.version = line
+			break
+		}
+	}
+	if l.version == "" {
+		return nil, fmt.Errorf("embedded data has no version")
+	}
+
+	scanner = bufio.NewScanner(strings.NewReader(compoundFile))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("compound %q must have two parts", line)
+		}
+		l.AddCompound(parts[0], parts[1])
+	}
+
+	scanner = bufio.NewScanner(strings.NewReader(pronunciationFile))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("pronunciation %q has no phonemes", line)
+		}
+		l.AddPronunciation(parts[0], parts[1:])
+	}
+
+	return l, nil
+}
+
+// Version identifies the embedded data the lexicon was built from; lexicons
+// built with New have no version
+func (l *Lexicon) Version() string {
+	return l.version
+}
+
+// AddPronunciation records a word's ARPAbet phonemes, with stress digits
+func (l *Lexicon) AddPronunciation(word string, phonemes []string) {
+	key := Key(word)
+	if _, ok := l.pronunciations[key]; ok {
+		return
+	}
+	l.pronunciations[key] = append([]string(nil), phonemes...)
+	sound := SoundKey(phonemes)
+	l.bySound[sound] = append(l.bySound[sound], key)
+}
+
+// Pronunciation returns a word's ARPAbet phonemes, if the table has it
+func (l *Lexicon) Pronunciation(word string) ([]string, bool) {
+	phonemes, ok := l.pronunciations[Key(word)]
+	return append([]string(nil), phonemes...), ok
+}
+
+// WordsSounding returns the words whose pronunciation has the given SoundKey
+func (l *Lexicon) WordsSounding(sound string) []string {
+	return append([]string(nil), l.bySound[sound]...)
+}
+
+// SoundKey joins phonemes with their stress digits removed, so words that
+// sound the same share a key regardless of stress
+func SoundKey(phonemes []string) string {
+	stripped := make([]string, len(phonemes))
+	for i, phoneme := range phonemes {
+		stripped[i] = strings.TrimRight(phoneme, "012")
+	}
+	return strings.Join(stripped, " ")
+}
+
+// AddCompound records that left+right form a compound word or phrase
+func (l *Lexicon) AddCompound(left, right string) {
+	c := Compound{Left: Key(left), Right: Key(right)}
+	for _, existing := range l.byLeft[c.Left] {
+		if existing == c {
+			return
+		}
+	}
+	l.byLeft[c.Left] = append(l.byLeft[c.Left], c)
+	l.byRight[c.Right] = append(l.byRight[c.Right], c)
+}
+
+// CompoundsStartingWith returns the compounds whose first part is word
+// (FIRE gives FIRE+ALARM, FIRE+WORK, ...)
+func (l *Lexicon) CompoundsStartingWith(word string) []Compound {
+	return append([]Compound(nil), l.byLeft[Key(word)]...)
+}
+
+// CompoundsEndingWith returns the compounds whose second part is word
+// (BALL gives BASKET+BALL, FOOT+BALL, ...)
+func (l *Lexicon) CompoundsEndingWith(word string) []Compound {
+	return append([]Compound(nil), l.byRight[Key(word)]...)
+}
+
+// add registers a category's words
+func (l *Lexicon) add(name string, words []string) {
+	if _, ok := l.categories[name]; !ok {
+		l.names = append(l.names, name)
+	}
+	for _, word := range words {
+		key := Key(word)
+		if key == "" || containsString(l.categories[name], key) {
+			continue
+		}
+		l.categories[name] = append(l.categories[name], key)
+		l.byWord[key] = append(l.byWord[key], name)
+	}
+}
+
+// Key is the form entries are stored and looked up in, so "Café" finds CAFE
+// and "go-fish" finds GO FISH
+func Key(word string) string {
+	return normalize.Canonical(word)
+}
+
+// Categories returns the sorted category names
+func (l *Lexicon) Categories() []string {
+	return append([]string(nil), l.names...)
+}
+
+// Words returns the members of a category
+func (l *Lexicon) Words(category string) []string {
+	return append([]string(nil), l.categories[category]...)
+}
+
+// CategoriesOf returns the categories a word belongs to
+func (l *Lexicon) CategoriesOf(word string) []string {
+	return append([]string(nil), l.byWord[Key(word)]...)
+}
+
+// Contains reports whether the word is in any category
+func (l *Lexicon) Contains(word string) bool {
+	return len(l.byWord[Key(word)]) > 0
+}
+
+// InCategory reports whether the word is a member of the category
+func (l *Lexicon) InCategory(category, word string) bool {
+	return containsString(l.byWord[Key(word)], category)
+}
+
+// AllWords returns every word in the lexicon, sorted
+func (l *Lexicon) AllWords() []string {
+	words := make([]string, 0, len(l.byWord))
+	for word := range l.byWord {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package lexicon
+
+import (
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	l := Default()
+
+	if len(l.Categories()) == 0 {
+		t.Fatal("expected embedded categories")
+	}
+
+	categories := l.CategoriesOf("melon")
+	if len(categories) != 1 || categories[0] != "fruits" {
+		t.Errorf("expected MELON in fruits, got %v", categories)
+	}
+	if !l.Contains("SOLE") {
+		t.Error("expected SOLE in the lexicon")
+	}
+	if l.Contains("NOT A WORD") {
+		t.Error("expected unknown entry to be missing")
+	}
+}
+
+func TestNew(t *testing.T) {
+	l := New(map[string][]string{
+		"suits": {"club", "DIAMOND", "Heart", "SPADE", "club"},
+	})
+
+	words := l.Words("suits")
+	if len(words) != 4 {
+		t.Fatalf("expected 4 unique words, got %v", words)
+	}
+	if words[0] != "CLUB" {
+		t.Errorf("expected entries stored upper-case, got %q", words[0])
+	}
+}
+
+func TestKnowledgeBase(t *testing.T) {
+	l := Default()
+
+	if l.Version() == "" {
+		t.Error("expected the embedded data to be versioned")
+	}
+	if New(nil).Version() != "" {
+		t.Error("expected a lexicon built with New to have no version")
+	}
+
+	tests := []struct {
+		category string
+		word     string
+		member   bool
+	}{
+		{"fish", "sole", true},
+		{"body parts", "SOLE", true},
+		{"NFL teams", "JETS", true},
+		{"Greek letters", "OMEGA", true},
+		{"golf clubs", "NIBLICK", true},
+		{"card games", "GO FISH", true},
+		{"fish", "JETS", false},
+		{"no such category", "SOLE", false},
+	}
+	for _, tt := range tests {
+		if got := l.InCategory(tt.category, tt.word); got != tt.member {
+			t.Errorf("InCategory(%q, %q) = %v, expected %v", tt.category, tt.word, got, tt.member)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Canonical returns the form entries are compared in: "Café", "CAFE" and
+// " cafe " are all "CAFE", "rock 'n' roll" is "ROCK N ROLL" and "X-ray" is
+// "X RAY". Apostrophes are dropped so "DON'T" stays one word; other
+// punctuation and hyphens separate words. Tiles are kept as entered for
+// display and only compared in this form.
+func Canonical(entry string) string {
+	var b strings.Builder
+	pendingSpace := false
+	for _, r := range entry {
+		switch {
+		case isApostrophe(r), unicode.IsMark(r):
+			// Combining accents are dropped along with precomposed ones
+			continue
+		case r == '&':
+			// "R&B" is "R AND B"
+			pendingSpace = b.Len() > 0
+			writeToken(&b, "AND", &pendingSpace)
+			pendingSpace = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			writeToken(&b, fold(r), &pendingSpace)
+		default:
+			// Spaces, hyphens, slashes and other punctuation separate words
+			pendingSpace = b.Len() > 0
+		}
+	}
+	return b.String()
+}
+
+// writeToken appends s, preceded by a single space if one is pending
+func writeToken(b *strings.Builder, s string, pendingSpace *bool) {
+	if *pendingSpace && b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	*pendingSpace = false
+	b.WriteString(s)
+}
+
+// Tokens returns the canonical words of an entry
+func Tokens(entry string) []string {
+	return strings.Fields(Canonical(entry))
+}
+
+// Letters returns the canonical letters and digits of an entry with the
+// spaces between words removed ("Bald Eagle" is "BALDEAGLE"), the form
+// spelling-based analysis works on
+func Letters(entry string) string {
+	return strings.ReplaceAll(Canonical(entry), " ", "")
+}
+
+// Length returns the number of letters and digits in an entry, counting
+// runes rather than bytes ("CAFÉ" has 4)
+func Length(entry string) int {
+	return len([]rune(Letters(entry)))
+}
+
+// Equal reports whether two entries have the same canonical form
+func Equal(first, second string) bool {
+	return Canonical(first) == Canonical(second)
+}
+
+// isApostrophe matches the straight and curly apostrophes puzzles use
+func isApostrophe(r rune) bool {
+	switch r {
+	case '\'', '’', '‘', 'ʼ', '`':
+		return true
+	}
+	return false
+}
+
+// foldings maps accented and ligature capitals to plain ASCII
+var foldings = map[rune]string{}
+
+func init() {
+	groups := map[string]string{
+		"ÀÁÂÃÄÅĀĂĄ": "A", "Æ": "AE", "ÇĆĈĊČ": "C", "ĎĐ": "D", "ÈÉÊËĒĔĖĘĚ": "E",
+		"ĜĞĠĢ": "G", "ĤĦ": "H", "ÌÍÎÏĨĪĬĮİ": "I", "Ĵ": "J", "Ķ": "K", "ĹĻĽĿŁ": "L",
+		"ÑŃŅŇ": "N", "ÒÓÔÕÖØŌŎŐ": "O", "Œ": "OE", "ŔŖŘ": "R", "ŚŜŞŠ": "S", "ẞß": "SS",
+		"ŢŤŦ": "T", "ÙÚÛÜŨŪŬŮŰŲ": "U", "Ŵ": "W", "ÝŸŶ": "Y", "ŹŻŽ": "Z", "Þ": "TH",
+	}
+	for runes, plain := range groups {
+		for _, r := range runes {
+			foldings[r] = plain
+		}
+	}
+}
+
+// fold upper-cases a letter and strips any accent
+func fold(r rune) string {
+	upper := unicode.ToUpper(r)
+	if plain, ok := foldings[upper]; ok {
+		return plain
+	}
+	return string(upper)
+}
@@ -0,0 +1,48 @@
+package normalize
+
+import (
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		entry     string
+		canonical string
+		letters   string
+		length    int
+	}{
+		{"cafe", "CAFE", "CAFE", 4},
+		{"CAFÉ", "CAFE", "CAFE", 4},
+		{"Café", "CAFE", "CAFE", 4}, // combining acute accent
+		{"  Bald   Eagle ", "BALD EAGLE", "BALDEAGLE", 9},
+		{"X-ray", "X RAY", "XRAY", 4},
+		{"DON'T", "DONT", "DONT", 4},
+		{"rock ’n’ roll", "ROCK N ROLL", "ROCKNROLL", 9},
+		{"R&B", "R AND B", "RANDB", 5},
+		{"Straße", "STRASSE", "STRASSE", 7},
+		{"49ers!", "49ERS", "49ERS", 5},
+		{"...", "", "", 0},
+	}
+
+	for _, tt := range tests {
+		if got := Canonical(tt.entry); got != tt.canonical {
+			t.Errorf("Canonical(%q) = %q, expected %q", tt.entry, got, tt.canonical)
+		}
+		if got := Letters(tt.entry); got != tt.letters {
+			t.Errorf("Letters(%q) = %q, expected %q", tt.entry, got, tt.letters)
+		}
+		if got := Length(tt.entry); got != tt.length {
+			t.Errorf("Length(%q) = %d, expected %d", tt.entry, got, tt.length)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tokens := Tokens(" Bald Eagle ")
+	if len(tokens) != 2 || tokens[0] != "BALD" || tokens[1] != "EAGLE" {
+		t.Errorf("expected tokens BALD and EAGLE, got %v", tokens)
+	}
+	if !Equal("café", "CAFE") || Equal("CAFE", "CAGE") {
+		t.Error("expected entries to compare by canonical form")
+	}
+}
@@ -0,0 +1,71 @@
+package solver
+
+import (
+	"connections/pkg/calibration"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SetCalibrator makes the solver report calibrated confidences; nil turns
+// calibration off
+func (s *Solver) SetCalibrator(c *calibration.Calibrator) {
+	s.calibrator = c
+}
+
+// patternCandidates returns the grouper's candidates with calibrated confidences
+func (s *Solver) patternCandidates(words []string) []Group {
+	groups := patternGroups(s.grouper.FindGroups(words))
+	s.calibrate(groups)
+	return groups
+}
+
+// calibrate replaces raw confidences with calibrated ones
+func (s *Solver) calibrate(groups []Group) {
+	if s.calibrator == nil {
+		return
+	}
+	for i := range groups {
+		groups[i].Confidence = s.calibrator.Calibrate(sourceFamily(groups[i].Source), groups[i].Strategy, groups[i].Confidence)
+	}
+}
+
+// Observe runs the solver's raw candidate generators on an archived puzzle
+// and labels each candidate against the known answer, producing the
+// observations calibration.Fit learns from
+func (s *Solver) Observe(ctx context.Context, puzzle calibration.Puzzle) ([]calibration.Observation, error) {
+	if len(puzzle.Words) != 16 {
+		return nil, fmt.Errorf("expected 16 words, got %d", len(puzzle.Words))
+	}
+
+	candidates := patternGroups(s.grouper.FindGroups(puzzle.Words))
+
+	if s.aiEnabled() {
+		aiGroups, err := s.askAI(ctx, puzzle.Words)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			fmt.Printf("AI analysis failed (%v), observing pattern matching only...\n", err)
+		}
+		candidates = append(candidates, aiGroups...)
+	}
+
+	observations := make([]calibration.Observation, 0, len(candidates))
+	for _, candidate := range candidates {
+		observations = append(observations, calibration.Observation{
+			Source:     sourceFamily(candidate.Source),
+			Strategy:   candidate.Strategy,
+			Confidence: candidate.Confidence,
+			Correct:    puzzle.Label(candidate.Words),
+		})
+	}
+
+	return observations, nil
+}
+
+// sourceFamily folds ensemble sources such as "ai:gemini+claude" into "ai"
+func sourceFamily(source string) string {
+	family, _, _ := strings.Cut(source, ":")
+	return family
+}
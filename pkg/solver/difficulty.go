@@ -0,0 +1,113 @@
+package solver
+
+import (
+	"connections/pkg/grouper"
+	"sort"
+	"strings"
+)
+
+// Difficulty is the colour tier the game gives a group, easiest first
+type Difficulty int
+
+const (
+	// DifficultyUnknown means no prediction was made
+	DifficultyUnknown Difficulty = iota
+	// Yellow is the most straightforward group
+	Yellow
+	// Green is a moderately easy group
+	Green
+	// Blue is a moderately hard group
+	Blue
+	// Purple is the trickiest group, usually wordplay
+	Purple
+)
+
+// String returns the colour name
+func (d Difficulty) String() string {
+	switch d {
+	case Yellow:
+		return "yellow"
+	case Green:
+		return "green"
+	case Blue:
+		return "blue"
+	case Purple:
+		return "purple"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseDifficulty converts a colour name, as reported by an AI, into a Difficulty
+func ParseDifficulty(name string) Difficulty {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "yellow":
+		return Yellow
+	case "green":
+		return Green
+	case "blue":
+		return Blue
+	case "purple":
+		return Purple
+	default:
+		return DifficultyUnknown
+	}
+}
+
+// strategyDifficulty is the usual tier for groups found by each pattern
+// strategy; spelling tricks are what the game saves for purple
+var strategyDifficulty = map[string]Difficulty{
+	grouper.StrategyPrefix:    Purple,
+	grouper.StrategySuffix:    Purple,
+	grouper.StrategyLength:    Purple,
+	grouper.StrategyCompound:  Blue,
+	grouper.StrategyAnagram:   Purple,
+	grouper.StrategyHidden:    Purple,
+	grouper.StrategyHomophone: Purple,
+	grouper.StrategyRhyme:     Purple,
+	grouper.StrategyEdit:      Purple,
+}
+
+// predictDifficulty estimates a group's tier from the AI's own rating,
+// the strategy that found it, or failing those its confidence
+func predictDifficulty(group Group) Difficulty {
+	if group.Difficulty != DifficultyUnknown {
+		return group.Difficulty
+	}
+	if d, ok := strategyDifficulty[group.Strategy]; ok {
+		return d
+	}
+
+	switch {
+	case group.Confidence >= 0.9:
+		return Yellow
+	case group.Confidence >= 0.75:
+		return Green
+	case group.Confidence >= 0.6:
+		return Blue
+	default:
+		return Purple
+	}
+}
+
+// orderByDifficulty predicts a tier for every group and sorts the groups
+// easiest first, breaking ties by confidence. A complete puzzle has one group
+// of each colour, so four groups are given distinct colours in that order.
+func orderByDifficulty(groups []Group) {
+	for i := range groups {
+		groups[i].Difficulty = predictDifficulty(groups[i])
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Difficulty != groups[j].Difficulty {
+			return groups[i].Difficulty < groups[j].Difficulty
+		}
+		return groups[i].Confidence > groups[j].Confidence
+	})
+
+	if len(groups) == 4 {
+		for i := range groups {
+			groups[i].Difficulty = Yellow + Difficulty(i)
+		}
+	}
+}
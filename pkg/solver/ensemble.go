@@ -0,0 +1,149 @@
+package solver
+
+import (
+	"connections/pkg/ai"
+	"connections/pkg/grouper"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NamedProvider pairs an AI provider with the name used to attribute its groups
+type NamedProvider struct {
+	Name     string
+	Provider ai.Provider
+}
+
+// NewEnsemble creates a Solver that asks every provider in parallel and
+// merges their answers by vote
+func NewEnsemble(providers ...NamedProvider) *Solver {
+	return &Solver{
+		grouper:  grouper.New(),
+		ensemble: providers,
+		useAI:    len(providers) > 0,
+	}
+}
+
+// vote is one distinct group proposed by one or more providers
+type vote struct {
+	group  Group
+	voters []string
+	missed float64 // Product of (1 - confidence) over voters
+}
+
+// solveWithEnsemble asks all providers concurrently and returns the
+// consensus partition of their merged groups
+func (s *Solver) solveWithEnsemble(ctx context.Context, words []string) ([]Group, error) {
+	answers := make([][]Group, len(s.ensemble))
+	errs := make([]error, len(s.ensemble))
+
+	var wg sync.WaitGroup
+	for i, np := range s.ensemble {
+		wg.Add(1)
+		go func(i int, np NamedProvider) {
+			defer wg.Done()
+			answers[i], errs[i] = s.askProvider(ctx, np.Provider, words)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", np.Name, errs[i])
+			}
+		}(i, np)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(s.ensemble))
+	defer s.dropUnusable(errs)
+	for i, np := range s.ensemble {
+		names[i] = np.Name
+	}
+
+	merged := mergeVotes(names, answers)
+	if len(merged) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		if err != nil {
+			fmt.Printf("Ensemble provider failed: %v\n", err)
+		}
+	}
+
+	partitions := findPartitions(ctx, words, merged, 1)
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("ensemble found no consistent groups")
+	}
+	return partitions[0].Groups, nil
+}
+
+// dropUnusable removes the providers whose errors will recur on every
+// request, such as a rejected API key, from the ensemble
+func (s *Solver) dropUnusable(errs []error) {
+	var kept []NamedProvider
+	for i, np := range s.ensemble {
+		if aiUnusable(errs[i]) {
+			fmt.Printf("Dropping %s from the ensemble: %v\n", np.Name, errs[i])
+			continue
+		}
+		kept = append(kept, np)
+	}
+	s.ensemble = kept
+	s.useAI = len(kept) > 0
+}
+
+// mergeVotes combines each provider's groups into one list. Identical word
+// sets are merged, and their confidence is the chance that at least one
+// voter is right (1 - Π(1 - c)), so agreement between models raises it.
+func mergeVotes(names []string, answers [][]Group) []Group {
+	var votes []*vote
+	index := make(map[string]*vote)
+
+	for i, groups := range answers {
+		for _, group := range groups {
+			key := groupKey(group.Words)
+			v, ok := index[key]
+			if !ok {
+				v = &vote{group: group, missed: 1}
+				index[key] = v
+				votes = append(votes, v)
+			} else if group.Confidence > v.group.Confidence {
+				// Keep the most confident model's theme and explanation
+				v.group.Theme = group.Theme
+				v.group.Explanation = group.Explanation
+				v.group.Difficulty = group.Difficulty
+			}
+			if !containsWord(v.voters, names[i]) {
+				v.voters = append(v.voters, names[i])
+				v.missed *= 1 - clampConfidence(group.Confidence)
+			}
+		}
+	}
+
+	merged := make([]Group, 0, len(votes))
+	for _, v := range votes {
+		group := v.group
+		group.Confidence = 1 - v.missed
+		group.Source = "ai:" + strings.Join(v.voters, "+")
+		merged = append(merged, group)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Confidence > merged[j].Confidence
+	})
+
+	return merged
+}
+
+// clampConfidence keeps a reported confidence within [0, 1]
+func clampConfidence(c float64) float64 {
+	if c < 0 {
+		return 0
+	}
+	if c > 1 {
+		return 1
+	}
+	return c
+}
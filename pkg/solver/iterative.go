@@ -0,0 +1,140 @@
+package solver
+
+import (
+	"connections/pkg/ai"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AIStrategy selects how the solver prompts an AI provider
+type AIStrategy int
+
+const (
+	// OneShot asks for all four groups in a single prompt
+	OneShot AIStrategy = iota
+	// Iterative asks for the most confident group, locks it in and re-asks
+	// on the remaining words, the way a human plays
+	Iterative
+)
+
+// String returns the strategy name used on the command line
+func (st AIStrategy) String() string {
+	switch st {
+	case OneShot:
+		return "oneshot"
+	case Iterative:
+		return "iterative"
+	default:
+		return fmt.Sprintf("AIStrategy(%d)", int(st))
+	}
+}
+
+// ParseAIStrategy converts a strategy name into an AIStrategy
+func ParseAIStrategy(name string) (AIStrategy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "oneshot", "one-shot":
+		return OneShot, nil
+	case "iterative":
+		return Iterative, nil
+	default:
+		return OneShot, fmt.Errorf("unknown AI strategy %q (want oneshot or iterative)", name)
+	}
+}
+
+// SetAIStrategy selects how AI providers are prompted
+func (s *Solver) SetAIStrategy(strategy AIStrategy) {
+	s.strategy = strategy
+}
+
+// solveIteratively locks in one group at a time, re-prompting with the words
+// that are left. The last four words are the last group without asking. If
+// a round fails, the groups found so far are returned so the caller can
+// complete the puzzle another way.
+func solveIteratively(ctx context.Context, provider ai.Provider, words []string) ([]Group, error) {
+	remaining := append([]string(nil), words...)
+	var groups []Group
+
+	for len(remaining) > 4 {
+		group, err := pickGroup(ctx, provider, remaining)
+		if err != nil {
+			if len(groups) == 0 || ctx.Err() != nil {
+				return nil, err
+			}
+			fmt.Printf("AI could not pick a group from %d words (%v)\n", len(remaining), err)
+			return groups, nil
+		}
+
+		groups = append(groups, group)
+		remaining = removeWords(remaining, group.Words)
+	}
+
+	if len(remaining) == 4 {
+		groups = append(groups, eliminationGroup(remaining, groups))
+	}
+	return groups, nil
+}
+
+// eliminationGroup is the group left once the others are locked in. It is
+// right only if all of them are, so its confidence is their product.
+func eliminationGroup(remaining []string, locked []Group) Group {
+	confidence := 1.0
+	for _, group := range locked {
+		confidence *= group.Confidence
+	}
+	return Group{
+		Words:      remaining,
+		Theme:      "Remaining words",
+		Confidence: confidence,
+		Source:     "elimination",
+	}
+}
+
+// pickGroup asks for the single most confident group among words, using
+// the provider's dedicated prompt when it has one
+func pickGroup(ctx context.Context, provider ai.Provider, words []string) (Group, error) {
+	var lastErr error
+	var feedback []string
+	for attempt := 1; attempt <= maxAIAttempts; attempt++ {
+		var suggestions []ai.SuggestedGroup
+		var err error
+		if picker, ok := provider.(ai.FeedbackPicker); ok && len(feedback) > 0 {
+			var suggestion ai.SuggestedGroup
+			suggestion, err = picker.PickGroupWithFeedback(ctx, words, feedback)
+			suggestions = []ai.SuggestedGroup{suggestion}
+		} else if picker, ok := provider.(ai.GroupPicker); ok {
+			var suggestion ai.SuggestedGroup
+			suggestion, err = picker.PickGroup(ctx, words)
+			suggestions = []ai.SuggestedGroup{suggestion}
+		} else {
+			suggestions, err = analyzeWords(ctx, provider, words, feedback)
+		}
+		if err != nil {
+			lastErr = err
+			if aiGaveUp(ctx, err) {
+				break
+			}
+			continue
+		}
+
+		valid, problems := reconcileGroups(words, suggestedGroups(suggestions))
+		if len(problems) > 0 {
+			fmt.Printf("AI answer needed fixes (attempt %d): %s\n", attempt, strings.Join(problems, "; "))
+		}
+		if len(valid) == 0 {
+			lastErr = fmt.Errorf("AI returned no valid group")
+			feedback = append(problems, "no group had 4 words from the list")
+			continue
+		}
+
+		best := valid[0]
+		for _, group := range valid[1:] {
+			if group.Confidence > best.Confidence {
+				best = group
+			}
+		}
+		return best, nil
+	}
+
+	return Group{}, lastErr
+}
@@ -0,0 +1,324 @@
+package solver
+
+import (
+	"connections/pkg/normalize"
+	"context"
+	"math/bits"
+	"sort"
+
+	"connections/pkg/grouper"
+)
+
+// Partition is a set of disjoint groups covering (some of) the puzzle words
+type Partition struct {
+	Groups []Group
+	Score  float64 // Sum of group confidences
+}
+
+// maxSearchWords is the largest word set the bitmask search can handle
+const maxSearchWords = 64
+
+// maxSearchNodes bounds the branches one search explores; past it the best
+// packings found so far are returned
+const maxSearchNodes = 200000
+
+// ctxCheckInterval is how many branches are explored between context checks
+const ctxCheckInterval = 1024
+
+// coverCandidate is a candidate group resolved to word positions
+type coverCandidate struct {
+	group Group
+	mask  uint64
+}
+
+// partitionSearch finds the best packings of candidate groups over a word set.
+// It is a backtracking exact-cover search (Algorithm X style): at each step it
+// picks the uncovered word with the fewest candidates and branches on them.
+// Words may also be left uncovered so that partial packings are still ranked
+// when no full partition exists. Branches that cannot beat the worst kept
+// packing are pruned, and the search stops early once it has explored
+// maxSearchNodes branches or its context is done.
+type partitionSearch struct {
+	ctx        context.Context
+	words      []string
+	candidates []coverCandidate
+	byWord     [][]int   // word index -> candidate indexes containing it, most confident first
+	wordBest   []float64 // word index -> best quarter-confidence of a candidate containing it
+	maxGroups  int
+	limit      int
+
+	nodes   int
+	stopped bool
+	chosen  []int
+	results []Partition
+}
+
+// findPartitions returns up to limit packings of disjoint candidates over words,
+// ordered by number of groups and then by total confidence (highest first).
+// If ctx ends or the node budget runs out, the best packings found so far
+// are returned.
+func findPartitions(ctx context.Context, words []string, candidates []Group, limit int) []Partition {
+	if len(words) == 0 || len(words) > maxSearchWords || limit <= 0 {
+		return nil
+	}
+
+	ps := newPartitionSearch(ctx, words, candidates, limit)
+	ps.search(0, 0)
+
+	return ps.results
+}
+
+// newPartitionSearch indexes the candidates for a search over words
+func newPartitionSearch(ctx context.Context, words []string, candidates []Group, limit int) *partitionSearch {
+	maxGroups := len(words) / 4
+	if maxGroups > 4 {
+		maxGroups = 4 // Cap at 4 for standard Connections puzzle
+	}
+
+	ps := &partitionSearch{
+		ctx:       ctx,
+		words:     words,
+		byWord:    make([][]int, len(words)),
+		wordBest:  make([]float64, len(words)),
+		maxGroups: maxGroups,
+		limit:     limit,
+	}
+
+	// Confident candidates first, so good packings are found early and
+	// prune the rest
+	resolved := resolveCandidates(words, candidates)
+	sort.SliceStable(resolved, func(i, j int) bool {
+		return resolved[i].group.Confidence > resolved[j].group.Confidence
+	})
+	for _, cc := range resolved {
+		idx := len(ps.candidates)
+		ps.candidates = append(ps.candidates, cc)
+		for i := range words {
+			if cc.mask&(1<<uint(i)) != 0 {
+				ps.byWord[i] = append(ps.byWord[i], idx)
+				// A group's confidence is shared by its 4 words
+				if share := cc.group.Confidence / 4; share > ps.wordBest[i] {
+					ps.wordBest[i] = share
+				}
+			}
+		}
+	}
+
+	return ps
+}
+
+// resolveCandidates maps candidate words to puzzle positions, dropping
+// candidates that reference unknown words and duplicate word sets (the
+// highest-confidence theme for a word set wins)
+func resolveCandidates(words []string, candidates []Group) []coverCandidate {
+	positions := make(map[string][]int)
+	for i, word := range words {
+		key := normalize.Canonical(word)
+		positions[key] = append(positions[key], i)
+	}
+
+	var resolved []coverCandidate
+	seen := make(map[uint64]int)
+
+	for _, candidate := range candidates {
+		if len(candidate.Words) != 4 {
+			continue
+		}
+		// Duplicate tiles are interchangeable, so a candidate naming one
+		// may sit on any of its positions
+		for _, mask := range candidateMasks(candidate.Words, positions, 0) {
+			if existing, ok := seen[mask]; ok {
+				if candidate.Confidence > resolved[existing].group.Confidence {
+					resolved[existing].group = candidate
+				}
+				continue
+			}
+			seen[mask] = len(resolved)
+			resolved = append(resolved, coverCandidate{group: candidate, mask: mask})
+		}
+	}
+
+	return resolved
+}
+
+// candidateMasks lists every way of placing words on distinct puzzle positions
+func candidateMasks(words []string, positions map[string][]int, used uint64) []uint64 {
+	if len(words) == 0 {
+		return []uint64{used}
+	}
+
+	var masks []uint64
+	for _, pos := range positions[normalize.Canonical(words[0])] {
+		bit := uint64(1) << uint(pos)
+		if used&bit == 0 {
+			masks = append(masks, candidateMasks(words[1:], positions, used|bit)...)
+		}
+	}
+	return masks
+}
+
+// search explores packings; covered holds words that are grouped or skipped
+func (ps *partitionSearch) search(covered uint64, score float64) {
+	if ps.stop() {
+		return
+	}
+	if len(ps.chosen) == ps.maxGroups {
+		ps.record(score)
+		return
+	}
+	if !ps.canImprove(covered, score) {
+		return
+	}
+
+	// Choose the open word with the fewest live candidates
+	best, bestCount := -1, -1
+	for i := range ps.words {
+		if covered&(1<<uint(i)) != 0 {
+			continue
+		}
+		count := 0
+		for _, ci := range ps.byWord[i] {
+			if ps.candidates[ci].mask&covered == 0 {
+				count++
+			}
+		}
+		if best == -1 || count < bestCount {
+			best, bestCount = i, count
+		}
+	}
+
+	if best == -1 {
+		ps.record(score)
+		return
+	}
+
+	for _, ci := range ps.byWord[best] {
+		cc := ps.candidates[ci]
+		if cc.mask&covered != 0 {
+			continue
+		}
+		ps.chosen = append(ps.chosen, ci)
+		ps.search(covered|cc.mask, score+cc.group.Confidence)
+		ps.chosen = ps.chosen[:len(ps.chosen)-1]
+	}
+
+	// Leave the word ungrouped, but only while enough words remain open
+	// to still reach the group count of the best packing found so far
+	open := len(ps.words) - bits.OnesCount64(covered) - 1
+	if len(ps.chosen)+open/4 >= ps.worstKeptGroups() {
+		ps.search(covered|1<<uint(best), score)
+	}
+}
+
+// stop reports whether the search should end, because the node budget is
+// spent or the context is done, and otherwise counts a branch
+func (ps *partitionSearch) stop() bool {
+	if ps.stopped {
+		return true
+	}
+	if ps.nodes >= maxSearchNodes || (ps.nodes%ctxCheckInterval == 0 && ps.ctx.Err() != nil) {
+		ps.stopped = true
+		return true
+	}
+	ps.nodes++
+	return false
+}
+
+// canImprove reports whether extending the current packing could still rank
+// within the limit. The score bound gives every open word the best share of
+// confidence any of its candidates offers, across the groups still to place.
+func (ps *partitionSearch) canImprove(covered uint64, score float64) bool {
+	if len(ps.results) < ps.limit {
+		return true
+	}
+	worst := ps.results[len(ps.results)-1]
+
+	var shares []float64
+	for i := range ps.words {
+		if covered&(1<<uint(i)) == 0 {
+			shares = append(shares, ps.wordBest[i])
+		}
+	}
+
+	slots := ps.maxGroups - len(ps.chosen)
+	if open := len(shares) / 4; open < slots {
+		slots = open
+	}
+	reachable := len(ps.chosen) + slots
+	if reachable != len(worst.Groups) {
+		return reachable > len(worst.Groups)
+	}
+
+	sort.Sort(sort.Reverse(sort.Float64Slice(shares)))
+	bound := score
+	for _, share := range shares[:4*slots] {
+		bound += share
+	}
+	// The tolerance keeps rounding in the bound from pruning an equal score
+	return bound+1e-9 > worst.Score
+}
+
+// worstKeptGroups is the group count a new packing must reach to be kept
+func (ps *partitionSearch) worstKeptGroups() int {
+	if len(ps.results) < ps.limit {
+		return 0
+	}
+	return len(ps.results[len(ps.results)-1].Groups)
+}
+
+// record stores the current packing if it ranks within the limit
+func (ps *partitionSearch) record(score float64) {
+	if len(ps.chosen) == 0 {
+		return
+	}
+
+	groups := make([]Group, 0, len(ps.chosen))
+	for _, ci := range ps.chosen {
+		groups = append(groups, ps.candidates[ci].group)
+	}
+	orderByDifficulty(groups)
+
+	p := Partition{Groups: groups, Score: score}
+	pos := sort.Search(len(ps.results), func(i int) bool {
+		return partitionLess(p, ps.results[i])
+	})
+	if pos >= ps.limit {
+		return
+	}
+
+	ps.results = append(ps.results, Partition{})
+	copy(ps.results[pos+1:], ps.results[pos:])
+	ps.results[pos] = p
+	if len(ps.results) > ps.limit {
+		ps.results = ps.results[:ps.limit]
+	}
+}
+
+// partitionLess reports whether a ranks ahead of b
+func partitionLess(a, b Partition) bool {
+	if len(a.Groups) != len(b.Groups) {
+		return len(a.Groups) > len(b.Groups)
+	}
+	return a.Score > b.Score
+}
+
+// patternGroups converts grouper candidates into solver groups
+func patternGroups(candidates []grouper.Candidate) []Group {
+	groups := make([]Group, 0, len(candidates))
+	for _, candidate := range candidates {
+		groups = append(groups, patternGroup(candidate))
+	}
+	return groups
+}
+
+// patternGroup converts a grouper candidate into a solver group
+func patternGroup(candidate grouper.Candidate) Group {
+	return Group{
+		Words:       candidate.Words,
+		Theme:       candidate.Theme,
+		Explanation: "",
+		Confidence:  candidate.Confidence,
+		Source:      "pattern",
+		Strategy:    candidate.Strategy,
+	}
+}
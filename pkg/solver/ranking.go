@@ -0,0 +1,158 @@
+package solver
+
+import (
+	"connections/pkg/normalize"
+	"context"
+	"sort"
+	"strings"
+)
+
+// Ranking holds alternative solutions for a puzzle and, for every word,
+// the candidate groups it could belong to
+type Ranking struct {
+	Partitions []Partition // Best first; Partitions[0] holds the groups Solve returns
+	Words      []WordFit   // One entry per puzzle word, in input order
+}
+
+// WordFit lists the themes a word could belong to, with the most confident
+// candidate group for each
+type WordFit struct {
+	Word   string
+	Groups []Group // One per theme, highest confidence first
+}
+
+// Ambiguous reports whether the word fits more than one theme
+func (w WordFit) Ambiguous() bool {
+	return len(w.Groups) > 1
+}
+
+// Themes returns the themes of the groups the word fits
+func (w WordFit) Themes() []string {
+	themes := make([]string, 0, len(w.Groups))
+	for _, group := range w.Groups {
+		themes = append(themes, group.Theme)
+	}
+	return themes
+}
+
+// Ambiguous returns the words that fit more than one theme
+func (r *Ranking) Ambiguous() []WordFit {
+	var ambiguous []WordFit
+	for _, fit := range r.Words {
+		if fit.Ambiguous() {
+			ambiguous = append(ambiguous, fit)
+		}
+	}
+	return ambiguous
+}
+
+// SolveRanked solves the puzzle and returns up to n complete or partial
+// partitions with scores, plus a per-word report of competing groups so
+// red herrings can be flagged. The first partition is the Solve result;
+// any error Solve reports is returned alongside the ranking.
+func (s *Solver) SolveRanked(ctx context.Context, words []string, n int) (*Ranking, error) {
+	if n < 1 {
+		n = 1
+	}
+
+	groups, solveErr := s.Solve(ctx, words)
+	if len(groups) == 0 && solveErr != nil {
+		return nil, solveErr
+	}
+
+	candidates := append(append([]Group(nil), groups...), s.patternCandidates(words)...)
+	candidates = uniqueGroups(candidates)
+
+	primary := Partition{Groups: groups}
+	for _, group := range groups {
+		primary.Score += group.Confidence
+	}
+
+	ranking := &Ranking{Partitions: []Partition{primary}}
+	seen := map[string]bool{partitionKey(primary): true}
+	for _, p := range findPartitions(ctx, words, candidates, n+1) {
+		if len(ranking.Partitions) >= n {
+			break
+		}
+		key := partitionKey(p)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ranking.Partitions = append(ranking.Partitions, p)
+	}
+
+	ranking.Words = wordFits(words, candidates)
+
+	return ranking, solveErr
+}
+
+// wordFits lists, for every word, the themes of the candidates containing
+// it. Many quartets from one oversized bucket share a theme, so only the
+// most confident group per theme is kept.
+func wordFits(words []string, candidates []Group) []WordFit {
+	fits := make([]WordFit, 0, len(words))
+	for _, word := range words {
+		var matching []Group
+		for _, candidate := range candidates {
+			if containsWord(candidate.Words, word) {
+				matching = append(matching, candidate)
+			}
+		}
+		sort.SliceStable(matching, func(i, j int) bool {
+			return matching[i].Confidence > matching[j].Confidence
+		})
+
+		fit := WordFit{Word: word}
+		themes := make(map[string]bool)
+		for _, group := range matching {
+			theme := normalize.Canonical(group.Theme)
+			if themes[theme] {
+				continue
+			}
+			themes[theme] = true
+			fit.Groups = append(fit.Groups, group)
+		}
+		fits = append(fits, fit)
+	}
+	return fits
+}
+
+// uniqueGroups drops groups whose word set already appeared, keeping the
+// highest-confidence one
+func uniqueGroups(groups []Group) []Group {
+	var unique []Group
+	index := make(map[string]int)
+	for _, group := range groups {
+		key := groupKey(group.Words)
+		if i, ok := index[key]; ok {
+			if group.Confidence > unique[i].Confidence {
+				unique[i] = group
+			}
+			continue
+		}
+		index[key] = len(unique)
+		unique = append(unique, group)
+	}
+	return unique
+}
+
+// groupKey identifies a group by its words, ignoring order and spelling variants
+func groupKey(words []string) string {
+	keys := make([]string, len(words))
+	for i, word := range words {
+		keys[i] = normalize.Canonical(word)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "|")
+}
+
+// partitionKey identifies a partition by its groups, ignoring order
+func partitionKey(p Partition) string {
+	keys := make([]string, len(p.Groups))
+	for i, group := range p.Groups {
+		keys[i] = groupKey(group.Words)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "/")
+}
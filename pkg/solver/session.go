@@ -0,0 +1,370 @@
+package solver
+
+import (
+	"connections/pkg/normalize"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaxMistakes is the number of wrong guesses allowed before the game is lost
+const MaxMistakes = 4
+
+// sessionAlternatives is how many partitions a session considers per guess
+const sessionAlternatives = 25
+
+// oneAwayConfidence is the confidence given to groups inferred from "one away" feedback
+const oneAwayConfidence = 0.25
+
+// Feedback is the game's response to a guess
+type Feedback int
+
+const (
+	// Correct means all four words form a group
+	Correct Feedback = iota
+	// OneAway means exactly three of the four words share a group
+	OneAway
+	// Wrong means no three of the four words share a group
+	Wrong
+)
+
+// String returns the feedback as the game displays it
+func (f Feedback) String() string {
+	switch f {
+	case Correct:
+		return "correct"
+	case OneAway:
+		return "one away"
+	case Wrong:
+		return "wrong"
+	default:
+		return fmt.Sprintf("Feedback(%d)", int(f))
+	}
+}
+
+// Guess is a submitted group of four words and the feedback it received
+type Guess struct {
+	Words    []string
+	Feedback Feedback
+}
+
+// Session plays a puzzle guess by guess, pruning candidate groups using
+// the feedback from each guess
+type Session struct {
+	remaining  []string
+	candidates []Group
+	solved     []Group
+	guesses    []Guess
+	mistakes   int
+}
+
+// NewSession starts an interactive session for the 16 puzzle words.
+// Candidate groups come from pattern matching and, if enabled, one AI analysis.
+func (s *Solver) NewSession(ctx context.Context, words []string) (*Session, error) {
+	if len(words) != 16 {
+		return nil, fmt.Errorf("expected 16 words, got %d", len(words))
+	}
+
+	candidates := s.patternCandidates(words)
+
+	if s.aiEnabled() {
+		aiGroups, err := s.solveWithAI(ctx, words)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			fmt.Printf("AI analysis failed (%v), using pattern matching only...\n\n", err)
+		}
+		candidates = append(aiGroups, candidates...)
+	}
+
+	return &Session{
+		remaining:  append([]string(nil), words...),
+		candidates: candidates,
+	}, nil
+}
+
+// Remaining returns the words not yet placed in a solved group
+func (ss *Session) Remaining() []string {
+	return append([]string(nil), ss.remaining...)
+}
+
+// Solved returns the groups confirmed correct so far
+func (ss *Session) Solved() []Group {
+	return append([]Group(nil), ss.solved...)
+}
+
+// Guesses returns every guess recorded so far
+func (ss *Session) Guesses() []Guess {
+	return append([]Guess(nil), ss.guesses...)
+}
+
+// Mistakes returns the number of "one away" and "wrong" guesses so far
+func (ss *Session) Mistakes() int {
+	return ss.mistakes
+}
+
+// MistakesLeft returns how many more incorrect guesses are allowed
+func (ss *Session) MistakesLeft() int {
+	return MaxMistakes - ss.mistakes
+}
+
+// Won reports whether all four groups have been found
+func (ss *Session) Won() bool {
+	return len(ss.remaining) == 0
+}
+
+// Done reports whether the game is over, either won or out of mistakes
+func (ss *Session) Done() bool {
+	return ss.Won() || ss.mistakes >= MaxMistakes
+}
+
+// NextGuess returns the best group to guess next given all feedback so far
+func (ss *Session) NextGuess() (Group, error) {
+	if ss.Done() {
+		return Group{}, fmt.Errorf("game is over")
+	}
+
+	// The last four words must be the last group
+	if len(ss.remaining) == 4 {
+		return Group{
+			Words:      append([]string(nil), ss.remaining...),
+			Theme:      "Remaining words",
+			Confidence: 1.0,
+			Source:     "elimination",
+		}, nil
+	}
+
+	partitions := findPartitions(context.Background(), ss.remaining, ss.liveCandidates(), sessionAlternatives)
+
+	// Prefer partitions that explain every "one away" guess
+	best := -1
+	for i, p := range partitions {
+		if ss.consistent(p) {
+			best = i
+			break
+		}
+	}
+	if best == -1 && len(partitions) > 0 {
+		best = 0
+	}
+	if best == -1 {
+		return Group{}, fmt.Errorf("no candidate groups left for %s", strings.Join(ss.remaining, ", "))
+	}
+
+	for _, group := range partitions[best].Groups {
+		if !ss.guessed(group.Words) {
+			return group, nil
+		}
+	}
+
+	return Group{}, fmt.Errorf("every candidate group has already been guessed")
+}
+
+// Record applies the feedback for a guess of four remaining words
+func (ss *Session) Record(words []string, feedback Feedback) error {
+	if ss.Done() {
+		return fmt.Errorf("game is over")
+	}
+	if feedback != Correct && feedback != OneAway && feedback != Wrong {
+		return fmt.Errorf("unknown feedback %v", feedback)
+	}
+	if len(words) != 4 {
+		return fmt.Errorf("a guess must have 4 words, got %d", len(words))
+	}
+
+	seen := make(map[string]bool)
+	for _, word := range words {
+		key := normalize.Canonical(word)
+		if seen[key] {
+			return fmt.Errorf("word %q appears twice in guess", word)
+		}
+		seen[key] = true
+		if !containsWord(ss.remaining, word) {
+			return fmt.Errorf("word %q is not in the remaining words", word)
+		}
+	}
+	if ss.guessed(words) {
+		return fmt.Errorf("already guessed %s", strings.Join(words, ", "))
+	}
+
+	ss.guesses = append(ss.guesses, Guess{Words: append([]string(nil), words...), Feedback: feedback})
+
+	switch feedback {
+	case Correct:
+		ss.solved = append(ss.solved, ss.describe(words))
+		ss.remaining = removeWords(ss.remaining, words)
+	case OneAway:
+		ss.mistakes++
+		ss.addOneAwayCandidates(words)
+	case Wrong:
+		ss.mistakes++
+	}
+
+	return nil
+}
+
+// liveCandidates returns the candidates still possible given the feedback
+func (ss *Session) liveCandidates() []Group {
+	var live []Group
+	for _, candidate := range ss.candidates {
+		if ss.allowed(candidate.Words) {
+			live = append(live, candidate)
+		}
+	}
+	return live
+}
+
+// allowed reports whether a group is possible under every recorded guess
+func (ss *Session) allowed(words []string) bool {
+	if !ss.allRemaining(words) {
+		return false
+	}
+
+	for _, guess := range ss.guesses {
+		shared := overlap(words, guess.Words)
+		switch guess.Feedback {
+		case OneAway:
+			// Three of the guess belong together, so the full guess cannot
+			if shared == 4 {
+				return false
+			}
+		case Wrong:
+			// No three of the guess belong together
+			if shared >= 3 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// consistent reports whether a partition explains every "one away" guess
+func (ss *Session) consistent(p Partition) bool {
+	for _, guess := range ss.guesses {
+		if guess.Feedback != OneAway || !ss.allRemaining(guess.Words) {
+			continue
+		}
+		found := false
+		for _, group := range p.Groups {
+			if overlap(group.Words, guess.Words) == 3 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// addOneAwayCandidates adds every group keeping three of a "one away" guess
+// and swapping in one other remaining word, so the search can always
+// propose a follow-up guess even when no pattern matched
+func (ss *Session) addOneAwayCandidates(words []string) {
+	for drop := range words {
+		var kept []string
+		for i, word := range words {
+			if i != drop {
+				kept = append(kept, word)
+			}
+		}
+
+		for _, other := range ss.remaining {
+			if containsWord(words, other) {
+				continue
+			}
+			ss.candidates = append(ss.candidates, Group{
+				Words:       append(append([]string(nil), kept...), other),
+				Theme:       "One away from " + strings.Join(words, ", "),
+				Explanation: "Keeps three words of a guess that was one away",
+				Confidence:  oneAwayConfidence,
+				Source:      "feedback",
+			})
+		}
+	}
+}
+
+// describe returns the best known description of a confirmed group
+func (ss *Session) describe(words []string) Group {
+	var best *Group
+	for i := range ss.candidates {
+		candidate := &ss.candidates[i]
+		if overlap(candidate.Words, words) == 4 && (best == nil || candidate.Confidence > best.Confidence) {
+			best = candidate
+		}
+	}
+
+	group := Group{
+		Words:      append([]string(nil), words...),
+		Confidence: 1.0,
+		Source:     "feedback",
+	}
+	if best != nil {
+		group.Theme = best.Theme
+		group.Explanation = best.Explanation
+		group.Source = best.Source
+	}
+	return group
+}
+
+// guessed reports whether the exact set of words was already guessed
+func (ss *Session) guessed(words []string) bool {
+	for _, guess := range ss.guesses {
+		if overlap(words, guess.Words) == 4 {
+			return true
+		}
+	}
+	return false
+}
+
+// allRemaining reports whether every word is still unsolved
+func (ss *Session) allRemaining(words []string) bool {
+	for _, word := range words {
+		if !containsWord(ss.remaining, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlap counts the words two groups share (case-insensitive)
+func overlap(a, b []string) int {
+	count := 0
+	for _, word := range a {
+		if containsWord(b, word) {
+			count++
+		}
+	}
+	return count
+}
+
+// containsWord reports whether words contains word, comparing canonical forms
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if normalize.Equal(w, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeWords returns words without any of the removed words (one occurrence each)
+func removeWords(words, removed []string) []string {
+	toRemove := make(map[string]int)
+	for _, word := range removed {
+		toRemove[normalize.Canonical(word)]++
+	}
+
+	var result []string
+	for _, word := range words {
+		key := normalize.Canonical(word)
+		if toRemove[key] > 0 {
+			toRemove[key]--
+			continue
+		}
+		result = append(result, word)
+	}
+	return result
+}
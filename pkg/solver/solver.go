@@ -2,8 +2,11 @@ package solver
 
 import (
 	"connections/pkg/ai"
+	"connections/pkg/calibration"
 	"connections/pkg/grouper"
+	"context"
 	"fmt"
+	"strings"
 )
 
 // Group represents a potential grouping of words
@@ -12,14 +15,20 @@ type Group struct {
 	Theme       string
 	Explanation string
 	Confidence  float64
-	Source      string // "ai" or "pattern"
+	Source      string // "ai", "ai:<providers>" for ensembles, "pattern", or "elimination" for the last four words
+	Strategy    string // Grouper strategy for pattern groups
+	Difficulty  Difficulty
 }
 
 // Solver handles the logic for solving Connections puzzles
 type Solver struct {
 	grouper    *grouper.Grouper
 	aiProvider ai.Provider
+	ensemble   []NamedProvider
+	strategy   AIStrategy
+	calibrator *calibration.Calibrator
 	useAI      bool
+	aiErr      error // Why the last AI request failed, if it did
 }
 
 // New creates a new Solver instance with pattern matching only
@@ -57,15 +66,52 @@ func NewWithGemini(apiKey string) *Solver {
 	}
 }
 
-// Solve attempts to find the 4 groups from the 16 words
-func (s *Solver) Solve(words []string) ([]Group, error) {
+// NewWithProvider creates a new Solver instance backed by any AI provider,
+// e.g. one built with custom options
+func NewWithProvider(provider ai.Provider) *Solver {
+	return &Solver{
+		grouper:    grouper.New(),
+		aiProvider: provider,
+		useAI:      true,
+	}
+}
+
+// NewWithLocal creates a new Solver instance backed by a self-hosted model
+// at an OpenAI-compatible endpoint such as Ollama's
+func NewWithLocal(baseURL, model string) *Solver {
+	return &Solver{
+		grouper:    grouper.New(),
+		aiProvider: ai.NewLocalProvider(baseURL, model),
+		useAI:      true,
+	}
+}
+
+// SetGrouper replaces the pattern-matching grouper, e.g. one built from a config
+func (s *Solver) SetGrouper(g *grouper.Grouper) {
+	s.grouper = g
+}
+
+// Solve attempts to find the 4 groups from the 16 words, ordered easiest
+// first with a predicted difficulty for each.
+// The context bounds any AI request; cancelling it aborts the solve.
+func (s *Solver) Solve(ctx context.Context, words []string) ([]Group, error) {
+	groups, err := s.solve(ctx, words)
+	orderByDifficulty(groups)
+	return groups, err
+}
+
+// solve finds the groups, trying AI first and completing with patterns
+func (s *Solver) solve(ctx context.Context, words []string) ([]Group, error) {
 	if len(words) != 16 {
 		return nil, fmt.Errorf("expected 16 words, got %d", len(words))
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Try AI first if enabled
-	if s.useAI && s.aiProvider != nil {
-		aiGroups, err := s.solveWithAI(words)
+	if s.aiEnabled() {
+		aiGroups, err := s.solveWithAI(ctx, words)
 
 		if err == nil && len(aiGroups) == 4 {
 			// Got all 4 groups from AI - perfect!
@@ -92,7 +138,7 @@ func (s *Solver) Solve(words []string) ([]Group, error) {
 
 			// Try pattern matching on remaining words
 			if len(remainingWords) > 0 {
-				patternGroups, _ := s.solveWithPatterns(remainingWords)
+				patternGroups, _ := s.solveWithPatterns(ctx, remainingWords)
 
 				// Combine AI groups with pattern groups
 				allGroups := append(aiGroups, patternGroups...)
@@ -108,6 +154,11 @@ func (s *Solver) Solve(words []string) ([]Group, error) {
 			// Just return what AI found
 			return aiGroups, fmt.Errorf("could only find %d groups", len(aiGroups))
 		}
+		// A cancelled caller wants no answer at all
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
 		// If AI fails completely, fall back to pattern matching
 		if err != nil {
 			fmt.Printf("AI analysis failed (%v), falling back to pattern matching...\n\n", err)
@@ -115,74 +166,143 @@ func (s *Solver) Solve(words []string) ([]Group, error) {
 	}
 
 	// Use pattern matching
-	return s.solveWithPatterns(words)
+	return s.solveWithPatterns(ctx, words)
 }
 
-// solveWithAI uses AI to find groups
-func (s *Solver) solveWithAI(words []string) ([]Group, error) {
-	suggestions, err := s.aiProvider.AnalyzeWords(words)
-	if err != nil {
-		return nil, err
+// aiEnabled reports whether the solver has any AI provider to ask
+func (s *Solver) aiEnabled() bool {
+	return s.useAI && (s.aiProvider != nil || len(s.ensemble) > 0)
+}
+
+// solveWithAI uses AI to find groups, with calibrated confidences
+func (s *Solver) solveWithAI(ctx context.Context, words []string) ([]Group, error) {
+	groups, err := s.askAI(ctx, words)
+	s.calibrate(groups)
+	return groups, err
+}
+
+// askAI asks the ensemble if one is configured, otherwise the single
+// provider. A provider that fails authentication or is out of quota is not
+// asked again.
+func (s *Solver) askAI(ctx context.Context, words []string) ([]Group, error) {
+	if len(s.ensemble) > 0 {
+		groups, err := s.solveWithEnsemble(ctx, words)
+		s.aiErr = err
+		return groups, err
 	}
 
-	var result []Group
-	for _, suggestion := range suggestions {
-		result = append(result, Group{
-			Words:       suggestion.Words,
-			Theme:       suggestion.Theme,
-			Explanation: suggestion.Explanation,
-			Confidence:  suggestion.Confidence,
-			Source:      "ai",
-		})
+	groups, err := s.askProvider(ctx, s.aiProvider, words)
+	s.aiErr = err
+	if aiUnusable(err) {
+		fmt.Printf("Disabling AI for this solver: %v\n", err)
+		s.useAI = false
 	}
+	return groups, err
+}
 
-	return result, nil
+// AIError returns why the AI failed when it was last asked, or nil if it
+// answered. Match it with errors.Is against ai.ErrAuth, ai.ErrQuota,
+// ai.ErrRateLimited, ai.ErrBadRequest or ai.ErrUnavailable to tell a
+// settings problem from one worth retrying later. After an auth or quota
+// failure the AI is no longer asked, so that error stays.
+func (s *Solver) AIError() error {
+	return s.aiErr
 }
 
-// solveWithPatterns uses pattern matching to find groups
-func (s *Solver) solveWithPatterns(words []string) ([]Group, error) {
-	// Use the grouper to find potential groups
-	candidates := s.grouper.FindGroups(words)
+// askProvider asks one provider for groups using the configured strategy
+func (s *Solver) askProvider(ctx context.Context, provider ai.Provider, words []string) ([]Group, error) {
+	if s.strategy == Iterative {
+		return solveIteratively(ctx, provider, words)
+	}
+	return solveOneShot(ctx, provider, words)
+}
 
-	// Calculate how many groups we expect based on word count
-	// Each group has 4 words, so expected groups = words / 4
+// solveOneShot asks for all groups at once. Answers are reconciled against
+// the puzzle words, and the provider is asked again, told what was wrong,
+// if the partition is invalid.
+func solveOneShot(ctx context.Context, provider ai.Provider, words []string) ([]Group, error) {
 	expectedGroups := len(words) / 4
-	if expectedGroups > 4 {
-		expectedGroups = 4 // Cap at 4 for standard Connections puzzle
-	}
 
-	// Find non-overlapping groups
-	var result []Group
-	used := make(map[string]bool)
-
-	for _, candidate := range candidates {
-		// Check if any word is already used
-		hasUsed := false
-		for _, word := range candidate.Words {
-			if used[word] {
-				hasUsed = true
+	var best []Group
+	var lastErr error
+	var feedback []string
+	for attempt := 1; attempt <= maxAIAttempts; attempt++ {
+		suggestions, err := analyzeWords(ctx, provider, words, feedback)
+		if err != nil {
+			lastErr = err
+			if aiGaveUp(ctx, err) {
 				break
 			}
+			continue
 		}
 
-		if !hasUsed && len(result) < expectedGroups {
-			result = append(result, Group{
-				Words:       candidate.Words,
-				Theme:       candidate.Theme,
-				Explanation: "",
-				Confidence:  candidate.Confidence,
-				Source:      "pattern",
-			})
-
-			// Mark words as used
-			for _, word := range candidate.Words {
-				used[word] = true
-			}
+		valid, problems := reconcileGroups(words, suggestedGroups(suggestions))
+		if len(problems) > 0 {
+			fmt.Printf("AI answer needed fixes (attempt %d): %s\n", attempt, strings.Join(problems, "; "))
 		}
-
-		if len(result) == expectedGroups {
+		if len(valid) > len(best) {
+			best = valid
+		}
+		if len(best) >= expectedGroups {
 			break
 		}
+
+		feedback = problems
+		if len(valid) < expectedGroups {
+			feedback = append(feedback, fmt.Sprintf("only %d of the %d groups had 4 words from the list", len(valid), expectedGroups))
+		}
+	}
+
+	if len(best) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, fmt.Errorf("AI returned no valid groups")
+	}
+
+	return best, nil
+}
+
+// analyzeWords asks the provider for groups, passing on the problems found
+// in its previous answer when it accepts them
+func analyzeWords(ctx context.Context, provider ai.Provider, words, feedback []string) ([]ai.SuggestedGroup, error) {
+	if analyzer, ok := provider.(ai.FeedbackAnalyzer); ok && len(feedback) > 0 {
+		return analyzer.AnalyzeWithFeedback(ctx, words, feedback)
+	}
+	return provider.AnalyzeWords(ctx, words)
+}
+
+// suggestedGroups converts AI suggestions into solver groups
+func suggestedGroups(suggestions []ai.SuggestedGroup) []Group {
+	groups := make([]Group, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		groups = append(groups, Group{
+			Words:       suggestion.Words,
+			Theme:       suggestion.Theme,
+			Explanation: suggestion.Explanation,
+			Confidence:  suggestion.Confidence,
+			Source:      "ai",
+			Difficulty:  ParseDifficulty(suggestion.Difficulty),
+		})
+	}
+	return groups
+}
+
+// PatternPartitions returns up to n ways of splitting the words into disjoint
+// pattern groups, best first. The first entry is what Solve uses when it falls
+// back to pattern matching; the rest are the runner-up partitions. The
+// context bounds the search, which returns its best so far when it ends.
+func (s *Solver) PatternPartitions(ctx context.Context, words []string, n int) []Partition {
+	return findPartitions(ctx, words, s.patternCandidates(words), n)
+}
+
+// solveWithPatterns uses pattern matching to find groups
+func (s *Solver) solveWithPatterns(ctx context.Context, words []string) ([]Group, error) {
+	// Search all candidate groups for the highest-confidence partition
+	// rather than greedily taking the first non-overlapping ones
+	var result []Group
+	if partitions := s.PatternPartitions(ctx, words, 1); len(partitions) > 0 {
+		result = partitions[0].Groups
 	}
 
 	// Only return error if we're working with a full 16-word puzzle
@@ -1,7 +1,14 @@
 package solver
 
 import (
+	"connections/pkg/ai"
+	"connections/pkg/grouper"
+	"context"
+	"errors"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSolve(t *testing.T) {
@@ -30,7 +37,7 @@ func TestSolve(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := New()
-			groups, err := s.Solve(tt.words)
+			groups, err := s.Solve(context.Background(), tt.words)
 
 			if tt.expectError {
 				if err == nil {
@@ -56,3 +63,694 @@ func TestSolve(t *testing.T) {
 		})
 	}
 }
+
+func TestFindPartitions(t *testing.T) {
+	words := []string{
+		"A1", "A2", "A3", "A4",
+		"B1", "B2", "B3", "B4",
+		"C1", "C2", "C3", "C4",
+		"D1", "D2", "D3", "D4",
+	}
+
+	candidates := []Group{
+		// Red herring: highest confidence, but blocks a full partition
+		{Words: []string{"A1", "A2", "A3", "B1"}, Theme: "herring", Confidence: 0.9},
+		{Words: []string{"A1", "A2", "A3", "A4"}, Theme: "A", Confidence: 0.6},
+		{Words: []string{"B1", "B2", "B3", "B4"}, Theme: "B", Confidence: 0.6},
+		{Words: []string{"C1", "C2", "C3", "C4"}, Theme: "C", Confidence: 0.5},
+		{Words: []string{"D1", "D2", "D3", "D4"}, Theme: "D", Confidence: 0.5},
+		{Words: []string{"D1", "D2", "D3", "D4"}, Theme: "D duplicate", Confidence: 0.4},
+		{Words: []string{"C1", "C2", "C3", "NOPE"}, Theme: "unknown word", Confidence: 1.0},
+	}
+
+	partitions := findPartitions(context.Background(), words, candidates, 3)
+	if len(partitions) == 0 {
+		t.Fatal("expected at least one partition")
+	}
+
+	best := partitions[0]
+	if len(best.Groups) != 4 {
+		t.Fatalf("expected best partition to have 4 groups, got %d", len(best.Groups))
+	}
+	if best.Score < 2.19 || best.Score > 2.21 {
+		t.Errorf("expected best score 2.2, got %.2f", best.Score)
+	}
+	for _, group := range best.Groups {
+		if group.Theme == "herring" || group.Theme == "D duplicate" || group.Theme == "unknown word" {
+			t.Errorf("unexpected group %q in best partition", group.Theme)
+		}
+	}
+
+	for i := 1; i < len(partitions); i++ {
+		if len(partitions[i].Groups) > len(partitions[i-1].Groups) {
+			t.Errorf("partition %d ranks above a larger partition", i-1)
+		}
+	}
+}
+
+func TestFindPartitionsDuplicateWords(t *testing.T) {
+	words := []string{"FISH", "CAT", "DOG", "BIRD", "FISH", "SOLE", "BASS", "CARP"}
+
+	candidates := []Group{
+		{Words: []string{"FISH", "CAT", "DOG", "BIRD"}, Theme: "Pets", Confidence: 0.5},
+		{Words: []string{"FISH", "SOLE", "BASS", "CARP"}, Theme: "Fish", Confidence: 0.5},
+	}
+
+	partitions := findPartitions(context.Background(), words, candidates, 1)
+	if len(partitions) != 1 || len(partitions[0].Groups) != 2 {
+		t.Fatalf("expected one partition with 2 groups, got %+v", partitions)
+	}
+}
+
+func TestFindPartitionsPruning(t *testing.T) {
+	// Every quartet of 8 words, with distinct confidences
+	words := []string{"A", "B", "C", "D", "E", "F", "G", "H"}
+	var candidates []Group
+	for a := 0; a < 8; a++ {
+		for b := a + 1; b < 8; b++ {
+			for c := b + 1; c < 8; c++ {
+				for d := c + 1; d < 8; d++ {
+					candidates = append(candidates, Group{
+						Words:      []string{words[a], words[b], words[c], words[d]},
+						Confidence: float64((a*7+b*5+c*3+d*11)%17+1) / 20,
+					})
+				}
+			}
+		}
+	}
+
+	// Brute force: a full partition is a quartet and its complement
+	var want []float64
+	for i, first := range candidates {
+		for _, second := range candidates[i+1:] {
+			if overlap(first.Words, second.Words) == 0 {
+				want = append(want, first.Confidence+second.Confidence)
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(want)))
+
+	partitions := findPartitions(context.Background(), words, candidates, 3)
+	if len(partitions) != 3 {
+		t.Fatalf("expected 3 partitions, got %d", len(partitions))
+	}
+	for i, p := range partitions {
+		if diff := p.Score - want[i]; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("partition %d scores %.3f, want %.3f", i, p.Score, want[i])
+		}
+	}
+}
+
+func TestFindPartitionsBudget(t *testing.T) {
+	// Every quartet of 16 words at equal confidence has millions of packings
+	words := []string{
+		"A1", "A2", "A3", "A4", "B1", "B2", "B3", "B4",
+		"C1", "C2", "C3", "C4", "D1", "D2", "D3", "D4",
+	}
+	var candidates []Group
+	for a := 0; a < 16; a++ {
+		for b := a + 1; b < 16; b++ {
+			for c := b + 1; c < 16; c++ {
+				for d := c + 1; d < 16; d++ {
+					candidates = append(candidates, Group{Words: []string{words[a], words[b], words[c], words[d]}, Confidence: 0.5})
+				}
+			}
+		}
+	}
+
+	ps := newPartitionSearch(context.Background(), words, candidates, 3)
+	ps.search(0, 0)
+	if !ps.stopped || ps.nodes > maxSearchNodes {
+		t.Errorf("expected the search to stop at the node budget, explored %d (stopped %v)", ps.nodes, ps.stopped)
+	}
+	if len(ps.results) == 0 || len(ps.results[0].Groups) != 4 {
+		t.Errorf("expected the best packing found within the budget, got %+v", ps.results)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ps = newPartitionSearch(ctx, words, candidates, 3)
+	ps.search(0, 0)
+	if !ps.stopped || ps.nodes != 0 {
+		t.Errorf("expected a cancelled search to stop straight away, explored %d", ps.nodes)
+	}
+}
+
+func TestSessionFeedback(t *testing.T) {
+	words := []string{
+		"A1", "A2", "A3", "A4",
+		"B1", "B2", "B3", "B4",
+		"C1", "C2", "C3", "C4",
+		"D1", "D2", "D3", "D4",
+	}
+
+	ss := &Session{
+		remaining: append([]string(nil), words...),
+		candidates: []Group{
+			{Words: []string{"A1", "A2", "A3", "B1"}, Theme: "herring", Confidence: 0.9},
+			{Words: []string{"B2", "B3", "B4", "A4"}, Theme: "herring 2", Confidence: 0.9},
+			{Words: []string{"A1", "A2", "A3", "A4"}, Theme: "A", Confidence: 0.3},
+			{Words: []string{"B1", "B2", "B3", "B4"}, Theme: "B", Confidence: 0.3},
+			{Words: []string{"C1", "C2", "C3", "C4"}, Theme: "C", Confidence: 0.5},
+			{Words: []string{"D1", "D2", "D3", "D4"}, Theme: "D", Confidence: 0.5},
+		},
+	}
+
+	guess, err := ss.NextGuess()
+	if err != nil {
+		t.Fatalf("NextGuess() error = %v", err)
+	}
+	if guess.Theme != "herring" && guess.Theme != "herring 2" {
+		t.Fatalf("expected a red herring first, got %q", guess.Theme)
+	}
+
+	if err := ss.Record(guess.Words, OneAway); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if ss.Mistakes() != 1 {
+		t.Errorf("expected 1 mistake, got %d", ss.Mistakes())
+	}
+
+	for i := 0; i < 3; i++ {
+		guess, err = ss.NextGuess()
+		if err != nil {
+			t.Fatalf("NextGuess() error = %v", err)
+		}
+		if guess.Theme == "herring" || guess.Theme == "herring 2" {
+			t.Fatalf("red herring suggested again after one away")
+		}
+		if err := ss.Record(guess.Words, Correct); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	guess, err = ss.NextGuess()
+	if err != nil || guess.Source != "elimination" {
+		t.Fatalf("expected last group by elimination, got %+v, %v", guess, err)
+	}
+	if err := ss.Record(guess.Words, Correct); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if !ss.Won() || !ss.Done() {
+		t.Error("expected session to be won")
+	}
+}
+
+func TestSessionMistakeBudget(t *testing.T) {
+	s := New()
+	ss, err := s.NewSession(context.Background(), []string{
+		"A1", "A2", "A3", "A4",
+		"B1", "B2", "B3", "B4",
+		"C1", "C2", "C3", "C4",
+		"D1", "D2", "D3", "D4",
+	})
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+
+	guesses := [][]string{
+		{"A1", "B1", "C1", "D1"},
+		{"A2", "B2", "C2", "D2"},
+		{"A3", "B3", "C3", "D3"},
+		{"A4", "B4", "C4", "D4"},
+	}
+	for _, guess := range guesses {
+		if err := ss.Record(guess, Wrong); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	if !ss.Done() || ss.Won() {
+		t.Error("expected session to be lost after 4 mistakes")
+	}
+	if err := ss.Record([]string{"A1", "A2", "A3", "A4"}, Correct); err == nil {
+		t.Error("expected error recording a guess after the game is over")
+	}
+}
+
+func TestSolveRanked(t *testing.T) {
+	s := New()
+	words := []string{
+		"BLUE", "BLUR", "BLURT", "BLUSH",
+		"CRUSH", "BRUSH", "PLUSH", "FLUSH",
+		"CAT", "DOG", "EMU", "YAK",
+		"SNOWMAN", "SNOWCAP", "SNOWDAY", "SNOWFOX",
+	}
+
+	ranking, err := s.SolveRanked(context.Background(), words, 3)
+	if ranking == nil {
+		t.Fatalf("SolveRanked() returned no ranking: %v", err)
+	}
+	if len(ranking.Partitions) == 0 || len(ranking.Partitions) > 3 {
+		t.Fatalf("expected 1-3 partitions, got %d", len(ranking.Partitions))
+	}
+	if len(ranking.Words) != len(words) {
+		t.Fatalf("expected %d word fits, got %d", len(words), len(ranking.Words))
+	}
+
+	var blush *WordFit
+	for i := range ranking.Words {
+		if ranking.Words[i].Word == "BLUSH" {
+			blush = &ranking.Words[i]
+		}
+	}
+	if blush == nil || !blush.Ambiguous() {
+		t.Fatalf("expected BLUSH to be ambiguous, got %+v", blush)
+	}
+
+	found := false
+	for _, fit := range ranking.Ambiguous() {
+		if fit.Word == "BLUSH" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected BLUSH in ambiguous words")
+	}
+}
+
+func TestWordFitsByTheme(t *testing.T) {
+	words := []string{"BASS", "TROUT", "PERCH", "SOLE", "PIKE", "HEEL", "LACE", "TONGUE"}
+	candidates := []Group{
+		{Words: []string{"BASS", "TROUT", "PERCH", "SOLE"}, Theme: "Fish", Confidence: 0.9},
+		{Words: []string{"BASS", "TROUT", "PERCH", "PIKE"}, Theme: "Fish", Confidence: 0.8},
+		{Words: []string{"BASS", "TROUT", "SOLE", "PIKE"}, Theme: "fish", Confidence: 0.7},
+		{Words: []string{"SOLE", "HEEL", "LACE", "TONGUE"}, Theme: "Shoe parts", Confidence: 0.6},
+	}
+
+	themes := make(map[string][]string)
+	for _, fit := range wordFits(words, candidates) {
+		themes[fit.Word] = fit.Themes()
+		if fit.Word == "BASS" && fit.Ambiguous() {
+			t.Errorf("expected BASS to fit only Fish, got %v", fit.Themes())
+		}
+	}
+	if got := strings.Join(themes["BASS"], "/"); got != "Fish" {
+		t.Errorf("expected BASS to fit Fish once, got %q", got)
+	}
+	if got := strings.Join(themes["SOLE"], "/"); got != "Fish/Shoe parts" {
+		t.Errorf("expected SOLE to fit Fish and Shoe parts, got %q", got)
+	}
+}
+
+// blockingProvider waits for the context to end before returning
+type blockingProvider struct{}
+
+func (blockingProvider) AnalyzeWords(ctx context.Context, _ []string) ([]ai.SuggestedGroup, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// TestSolveRankedLargeBuckets solves boards where one strategy matches all
+// 16 words, which once offered the partition search every quartet
+func TestSolveRankedLargeBuckets(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+	}{
+		{
+			name: "one category",
+			words: []string{
+				"BEAR", "LION", "TIGER", "WOLF", "PIG", "RAT", "OX", "ANT",
+				"COW", "HEN", "ELK", "EMU", "YAK", "APE", "BAT", "BEE",
+			},
+		},
+		{
+			name: "one prefix",
+			words: []string{
+				"STRAW", "STRAP", "STREAM", "STREET", "STRIKE", "STRING", "STRIPE", "STROKE",
+				"STRONG", "STRUCK", "STRAND", "STRESS", "STRICT", "STRIDE", "STROLL", "STRUT",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			if candidates := s.grouper.FindGroups(tt.words); len(candidates) > 200 {
+				t.Errorf("expected a bounded candidate list, got %d", len(candidates))
+			}
+
+			ranking, err := s.SolveRanked(context.Background(), tt.words, 3)
+			if err != nil || len(ranking.Partitions[0].Groups) != 4 {
+				t.Errorf("expected a full partition, got %v (error %v)", ranking, err)
+			}
+		})
+	}
+}
+
+func TestSolveCancelled(t *testing.T) {
+	s := New()
+	s.aiProvider = blockingProvider{}
+	s.useAI = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	words := []string{
+		"A1", "A2", "A3", "A4", "B1", "B2", "B3", "B4",
+		"C1", "C2", "C3", "C4", "D1", "D2", "D3", "D4",
+	}
+	groups, err := s.Solve(ctx, words)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("expected no groups after cancellation, got %d", len(groups))
+	}
+}
+
+func TestReconcileGroups(t *testing.T) {
+	words := []string{
+		"BASS", "TROUT", "PERCH", "SOLE",
+		"CLUB", "DIAMOND", "HEART", "SPADE",
+		"WOOD", "IRON", "DRIVER", "PUTTER",
+		"ACE", "KING", "QUEEN", "JACK",
+	}
+
+	groups := []Group{
+		{Words: []string{"bass", "Trouts", "PERCH", "sole."}, Theme: "Fish", Confidence: 0.9},
+		{Words: []string{"CLUB", "DIAMOND", "HEART", "SPADE"}, Theme: "Suits", Confidence: 0.95},
+		{Words: []string{"CLUB", "WOOD", "IRON", "DRIVER"}, Theme: "Golf", Confidence: 0.8},
+		{Words: []string{"ACE", "KING", "QUEEN", "JOKER"}, Theme: "Cards", Confidence: 0.7},
+	}
+
+	valid, problems := reconcileGroups(words, groups)
+	if len(valid) != 2 {
+		t.Fatalf("expected 2 valid groups, got %d: %+v", len(valid), valid)
+	}
+	if valid[0].Theme != "Fish" || valid[1].Theme != "Suits" {
+		t.Errorf("expected AI order to be kept, got %q, %q", valid[0].Theme, valid[1].Theme)
+	}
+	for i, want := range []string{"BASS", "TROUT", "PERCH", "SOLE"} {
+		if valid[0].Words[i] != want {
+			t.Errorf("expected repaired word %q, got %q", want, valid[0].Words[i])
+		}
+	}
+	if len(problems) == 0 {
+		t.Error("expected problems to be reported")
+	}
+}
+
+func TestReconcileKeepsDisplayForms(t *testing.T) {
+	words := []string{
+		"Café", "Piñata", "X-Ray", "Don't",
+		"BALD EAGLE", "ROCK 'N' ROLL", "R&B", "ÉCLAIR",
+	}
+	groups := []Group{
+		{Words: []string{"CAFE", "pinata", "x ray", "DONT"}, Theme: "Accents and punctuation", Confidence: 0.9},
+		{Words: []string{"Bald  Eagle", "rock n roll", "R AND B", "eclair"}, Theme: "Phrases", Confidence: 0.8},
+	}
+
+	valid, _ := reconcileGroups(words, groups)
+	if len(valid) != 2 {
+		t.Fatalf("expected both groups to match the puzzle, got %+v", valid)
+	}
+	for i, group := range valid {
+		for j, word := range group.Words {
+			if want := words[i*4+j]; word != want {
+				t.Errorf("expected the puzzle's display form %q, got %q", want, word)
+			}
+		}
+	}
+}
+
+// scriptedProvider returns one canned answer per call
+type scriptedProvider struct {
+	answers  [][]ai.SuggestedGroup
+	calls    int
+	feedback [][]string // The feedback each call was given
+}
+
+func (p *scriptedProvider) AnalyzeWords(ctx context.Context, words []string) ([]ai.SuggestedGroup, error) {
+	return p.AnalyzeWithFeedback(ctx, words, nil)
+}
+
+func (p *scriptedProvider) AnalyzeWithFeedback(_ context.Context, _, feedback []string) ([]ai.SuggestedGroup, error) {
+	answer := p.answers[p.calls]
+	p.calls++
+	p.feedback = append(p.feedback, feedback)
+	return answer, nil
+}
+
+func TestSolveWithAIReprompts(t *testing.T) {
+	words := []string{
+		"A1", "A2", "A3", "A4", "B1", "B2", "B3", "B4",
+		"C1", "C2", "C3", "C4", "D1", "D2", "D3", "D4",
+	}
+	good := []ai.SuggestedGroup{
+		{Words: []string{"A1", "A2", "A3", "A4"}, Theme: "A", Confidence: 0.9},
+		{Words: []string{"B1", "B2", "B3", "B4"}, Theme: "B", Confidence: 0.9},
+		{Words: []string{"C1", "C2", "C3", "C4"}, Theme: "C", Confidence: 0.9},
+		{Words: []string{"D1", "D2", "D3", "D4"}, Theme: "D", Confidence: 0.9},
+	}
+	bad := []ai.SuggestedGroup{
+		{Words: []string{"A1", "A2", "A3", "XX"}, Theme: "A", Confidence: 0.9},
+	}
+
+	provider := &scriptedProvider{answers: [][]ai.SuggestedGroup{bad, good}}
+	s := New()
+	s.aiProvider = provider
+	s.useAI = true
+
+	groups, err := s.Solve(context.Background(), words)
+	if err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	if provider.calls != 2 {
+		t.Fatalf("expected AI to be asked twice, got %d", provider.calls)
+	}
+	if len(groups) != 4 {
+		t.Errorf("expected 4 groups, got %d", len(groups))
+	}
+
+	if len(provider.feedback[0]) != 0 {
+		t.Errorf("expected no feedback on the first prompt, got %v", provider.feedback[0])
+	}
+	retry := strings.Join(provider.feedback[1], "\n")
+	for _, want := range []string{`"XX" is not in the puzzle`, "only 0 of the 4 groups"} {
+		if !strings.Contains(retry, want) {
+			t.Errorf("expected the re-prompt to mention %q, got %v", want, provider.feedback[1])
+		}
+	}
+}
+
+func TestEnsembleVoting(t *testing.T) {
+	words := []string{
+		"A1", "A2", "A3", "A4", "B1", "B2", "B3", "B4",
+		"C1", "C2", "C3", "C4", "D1", "D2", "D3", "D4",
+	}
+	shared := []ai.SuggestedGroup{
+		{Words: []string{"A1", "A2", "A3", "A4"}, Theme: "A", Confidence: 0.6},
+		{Words: []string{"B1", "B2", "B3", "B4"}, Theme: "B", Confidence: 0.6},
+	}
+	first := append([]ai.SuggestedGroup{
+		{Words: []string{"C1", "C2", "C3", "C4"}, Theme: "C", Confidence: 0.7},
+		{Words: []string{"D1", "D2", "D3", "D4"}, Theme: "D", Confidence: 0.7},
+	}, shared...)
+	second := append([]ai.SuggestedGroup{
+		{Words: []string{"C1", "C2", "D3", "D4"}, Theme: "CD", Confidence: 0.8},
+		{Words: []string{"D1", "D2", "C3", "C4"}, Theme: "DC", Confidence: 0.8},
+	}, shared...)
+
+	s := NewEnsemble(
+		NamedProvider{Name: "one", Provider: &scriptedProvider{answers: [][]ai.SuggestedGroup{first}}},
+		NamedProvider{Name: "two", Provider: &scriptedProvider{answers: [][]ai.SuggestedGroup{second}}},
+	)
+
+	groups, err := s.Solve(context.Background(), words)
+	if err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	if len(groups) != 4 {
+		t.Fatalf("expected 4 groups, got %d", len(groups))
+	}
+
+	for _, group := range groups {
+		switch group.Theme {
+		case "A", "B":
+			if group.Source != "ai:one+two" {
+				t.Errorf("expected shared group %q attributed to both, got %q", group.Theme, group.Source)
+			}
+			if group.Confidence < 0.83 || group.Confidence > 0.85 {
+				t.Errorf("expected boosted confidence 0.84 for %q, got %.2f", group.Theme, group.Confidence)
+			}
+		case "CD", "DC":
+			if group.Source != "ai:two" {
+				t.Errorf("expected %q attributed to two, got %q", group.Theme, group.Source)
+			}
+		default:
+			t.Errorf("unexpected group %q in consensus", group.Theme)
+		}
+	}
+}
+
+// failingProvider always fails with err
+type failingProvider struct {
+	err   error
+	calls int
+}
+
+func (p *failingProvider) AnalyzeWords(_ context.Context, _ []string) ([]ai.SuggestedGroup, error) {
+	p.calls++
+	return nil, p.err
+}
+
+func TestAIErrors(t *testing.T) {
+	words := []string{
+		"BASS", "TROUT", "PERCH", "SOLE", "CLUB", "DIAMOND", "HEART", "SPADE",
+		"WOOD", "IRON", "DRIVER", "PUTTER", "ACE", "KING", "QUEEN", "JACK",
+	}
+	authErr := &ai.APIError{Provider: "test", StatusCode: 401, Kind: ai.ErrAuth, Message: "bad key"}
+	rateErr := &ai.APIError{Provider: "test", StatusCode: 429, Kind: ai.ErrRateLimited, Message: "slow down"}
+
+	tests := []struct {
+		name         string
+		err          error
+		wantKind     error
+		wantDisabled bool
+	}{
+		{name: "auth failure disables AI", err: authErr, wantKind: ai.ErrAuth, wantDisabled: true},
+		{name: "rate limit keeps AI", err: rateErr, wantKind: ai.ErrRateLimited, wantDisabled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &failingProvider{err: tt.err}
+			s := NewWithProvider(provider)
+
+			_, _ = s.Solve(context.Background(), words)
+			if provider.calls != 1 {
+				t.Errorf("expected one request for an API error, got %d", provider.calls)
+			}
+			if !errors.Is(s.AIError(), tt.wantKind) {
+				t.Errorf("AIError() = %v, want %v", s.AIError(), tt.wantKind)
+			}
+
+			_, _ = s.Solve(context.Background(), words)
+			if disabled := provider.calls == 1; disabled != tt.wantDisabled {
+				t.Errorf("expected AI disabled = %v, got %d calls", tt.wantDisabled, provider.calls)
+			}
+		})
+	}
+
+	good := []ai.SuggestedGroup{
+		{Words: []string{"BASS", "TROUT", "PERCH", "SOLE"}, Theme: "Fish", Confidence: 0.9},
+		{Words: []string{"CLUB", "DIAMOND", "HEART", "SPADE"}, Theme: "Card suits", Confidence: 0.9},
+		{Words: []string{"WOOD", "IRON", "DRIVER", "PUTTER"}, Theme: "Golf clubs", Confidence: 0.9},
+		{Words: []string{"ACE", "KING", "QUEEN", "JACK"}, Theme: "Playing cards", Confidence: 0.9},
+	}
+	s := NewEnsemble(
+		NamedProvider{Name: "good", Provider: &scriptedProvider{answers: [][]ai.SuggestedGroup{good}}},
+		NamedProvider{Name: "unpaid", Provider: &failingProvider{err: &ai.APIError{Provider: "test", StatusCode: 429, Kind: ai.ErrQuota}}},
+	)
+	if _, err := s.Solve(context.Background(), words); err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	if len(s.ensemble) != 1 || s.ensemble[0].Name != "good" {
+		t.Errorf("expected the out-of-quota provider dropped, got %+v", s.ensemble)
+	}
+}
+
+// pickingProvider answers single-group prompts from a fixed solution
+type pickingProvider struct {
+	solution [][]string
+	asked    []int
+}
+
+func (p *pickingProvider) AnalyzeWords(_ context.Context, _ []string) ([]ai.SuggestedGroup, error) {
+	return nil, errors.New("one-shot prompt not expected")
+}
+
+func (p *pickingProvider) PickGroup(_ context.Context, words []string) (ai.SuggestedGroup, error) {
+	p.asked = append(p.asked, len(words))
+	for _, group := range p.solution {
+		if overlap(group, words) == 4 {
+			return ai.SuggestedGroup{Words: group, Theme: group[0][:1], Confidence: 0.9}, nil
+		}
+	}
+	return ai.SuggestedGroup{}, errors.New("no group left")
+}
+
+func TestSolveIteratively(t *testing.T) {
+	provider := &pickingProvider{solution: [][]string{
+		{"A1", "A2", "A3", "A4"},
+		{"B1", "B2", "B3", "B4"},
+		{"C1", "C2", "C3", "C4"},
+		{"D1", "D2", "D3", "D4"},
+	}}
+	s := New()
+	s.aiProvider = provider
+	s.useAI = true
+	s.SetAIStrategy(Iterative)
+
+	groups, err := s.Solve(context.Background(), []string{
+		"A1", "A2", "A3", "A4", "B1", "B2", "B3", "B4",
+		"C1", "C2", "C3", "C4", "D1", "D2", "D3", "D4",
+	})
+	if err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	if len(groups) != 4 {
+		t.Fatalf("expected 4 groups, got %d", len(groups))
+	}
+
+	// The last four words are locked without a prompt
+	want := []int{16, 12, 8}
+	if len(provider.asked) != len(want) {
+		t.Fatalf("expected %d prompts, got %v", len(want), provider.asked)
+	}
+	for i, n := range want {
+		if provider.asked[i] != n {
+			t.Errorf("prompt %d: expected %d words, got %d", i+1, n, provider.asked[i])
+		}
+	}
+
+	var last *Group
+	for i := range groups {
+		if groups[i].Source == "elimination" {
+			last = &groups[i]
+		}
+	}
+	if last == nil || strings.Join(last.Words, ",") != "D1,D2,D3,D4" {
+		t.Fatalf("expected D1..D4 by elimination, got %+v", groups)
+	}
+	if last.Confidence >= 1 || last.Confidence <= 0 {
+		t.Errorf("expected the locked groups' confidence to carry over, got %v", last.Confidence)
+	}
+}
+
+func TestOrderByDifficulty(t *testing.T) {
+	groups := []Group{
+		{Theme: "wordplay", Strategy: grouper.StrategySuffix, Confidence: 0.5},
+		{Theme: "rated blue", Difficulty: Blue, Confidence: 0.8},
+		{Theme: "sure thing", Confidence: 0.95},
+		{Theme: "fairly sure", Confidence: 0.8},
+	}
+
+	orderByDifficulty(groups)
+
+	want := []struct {
+		theme      string
+		difficulty Difficulty
+	}{
+		{"sure thing", Yellow},
+		{"fairly sure", Green},
+		{"rated blue", Blue},
+		{"wordplay", Purple},
+	}
+	for i, w := range want {
+		if groups[i].Theme != w.theme || groups[i].Difficulty != w.difficulty {
+			t.Errorf("position %d: expected %s %q, got %s %q", i, w.difficulty, w.theme, groups[i].Difficulty, groups[i].Theme)
+		}
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	if got := ParseDifficulty(" Purple "); got != Purple {
+		t.Errorf("expected purple, got %s", got)
+	}
+	if got := ParseDifficulty("orange"); got != DifficultyUnknown {
+		t.Errorf("expected unknown, got %s", got)
+	}
+}
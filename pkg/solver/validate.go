@@ -0,0 +1,152 @@
+package solver
+
+import (
+	"connections/pkg/ai"
+	"connections/pkg/normalize"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxAIAttempts is how many times the AI is asked before falling back
+const maxAIAttempts = 2
+
+// aiGaveUp reports whether asking the AI again cannot help: the caller
+// cancelled, or the service rejected the request or stayed unavailable
+// through the provider's own retries
+func aiGaveUp(ctx context.Context, err error) bool {
+	var apiErr *ai.APIError
+	return ctx.Err() != nil || errors.As(err, &apiErr)
+}
+
+// aiUnusable reports whether an AI error will recur on every request, so
+// the provider should not be asked again
+func aiUnusable(err error) bool {
+	return errors.Is(err, ai.ErrAuth) || errors.Is(err, ai.ErrQuota)
+}
+
+// wordMatcher resolves AI-reported words to the puzzle's own tiles
+type wordMatcher struct {
+	exact map[string]string   // canonical word -> puzzle word
+	loose map[string][]string // loose key -> puzzle words
+}
+
+// newWordMatcher indexes the puzzle words for exact and near-miss lookup
+func newWordMatcher(words []string) *wordMatcher {
+	m := &wordMatcher{
+		exact: make(map[string]string),
+		loose: make(map[string][]string),
+	}
+	for _, word := range words {
+		canonical := normalize.Canonical(word)
+		if _, ok := m.exact[canonical]; !ok {
+			m.exact[canonical] = word
+		}
+		key := looseKey(word)
+		if !containsWord(m.loose[key], word) {
+			m.loose[key] = append(m.loose[key], word)
+		}
+	}
+	return m
+}
+
+// match returns the puzzle word the AI meant, if it can be told unambiguously
+func (m *wordMatcher) match(word string) (string, bool) {
+	if puzzleWord, ok := m.exact[normalize.Canonical(word)]; ok {
+		return puzzleWord, true
+	}
+	if candidates := m.loose[looseKey(word)]; len(candidates) == 1 {
+		return candidates[0], true
+	}
+	return "", false
+}
+
+// looseKey reduces a word to its canonical letters and digits, singular,
+// so "Sole's", "SOLES" and "sole" all compare equal
+func looseKey(word string) string {
+	key := normalize.Letters(word)
+
+	switch {
+	case len(key) > 4 && strings.HasSuffix(key, "IES"):
+		key = strings.TrimSuffix(key, "IES") + "Y"
+	case len(key) > 3 && strings.HasSuffix(key, "ES") && strings.ContainsAny(key[len(key)-3:len(key)-2], "SXZH"):
+		key = strings.TrimSuffix(key, "ES")
+	case len(key) > 3 && strings.HasSuffix(key, "S") && !strings.HasSuffix(key, "SS"):
+		key = strings.TrimSuffix(key, "S")
+	}
+	return key
+}
+
+// reconcileGroups checks AI groups against the puzzle words. Spellings are
+// repaired to the puzzle's tiles, words not in the puzzle are dropped, and
+// a word claimed by several groups stays with the most confident one.
+// Groups left without four valid words are discarded. The returned problems
+// describe every repair or rejection.
+func reconcileGroups(words []string, groups []Group) ([]Group, []string) {
+	matcher := newWordMatcher(words)
+
+	// Tiles may repeat in a puzzle, so track how many of each remain
+	available := make(map[string]int)
+	for _, word := range words {
+		available[word]++
+	}
+
+	// Resolve conflicts in confidence order, but keep the AI's ordering
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return groups[order[i]].Confidence > groups[order[j]].Confidence
+	})
+
+	kept := make([]bool, len(groups))
+	repaired := make([]Group, len(groups))
+	var problems []string
+
+	for _, i := range order {
+		group := groups[i]
+		var matched []string
+		claimed := make(map[string]int)
+
+		for _, word := range group.Words {
+			puzzleWord, ok := matcher.match(word)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%q is not in the puzzle", word))
+				continue
+			}
+			if available[puzzleWord]-claimed[puzzleWord] <= 0 {
+				problems = append(problems, fmt.Sprintf("%q is already used by another group", puzzleWord))
+				continue
+			}
+			if puzzleWord != word {
+				problems = append(problems, fmt.Sprintf("repaired %q to %q", word, puzzleWord))
+			}
+			claimed[puzzleWord]++
+			matched = append(matched, puzzleWord)
+		}
+
+		if len(matched) != 4 {
+			problems = append(problems, fmt.Sprintf("dropped group %q: only %d valid words", group.Theme, len(matched)))
+			continue
+		}
+
+		for word, count := range claimed {
+			available[word] -= count
+		}
+		group.Words = matched
+		repaired[i] = group
+		kept[i] = true
+	}
+
+	var valid []Group
+	for i, group := range repaired {
+		if kept[i] {
+			valid = append(valid, group)
+		}
+	}
+
+	return valid, problems
+}